@@ -1,6 +1,11 @@
 import * as log from "@std/log";
 import { join } from "@std/path";
-import { getNextKana, jstTimeZone } from "./common.ts";
+import {
+  formatNextKana,
+  getNextKana,
+  getNextKanaSource,
+  jstTimeZone,
+} from "./common.ts";
 import { AppContext, EnvVars } from "./context.ts";
 import { RitrinPointTxRepo } from "./ritrin_point/tx.ts";
 import type { NostrEvent, NostrEventPre, NostrEventUnsigned } from "./types.ts";
@@ -59,7 +64,11 @@ const commands: CommandDef[] = [
     trigger: /next|次|つぎ|ツギ|[\u{23e9}\u{27a1}\u{1f51c}]/iu,
     handle: async (event, { env }) => {
       const next = await getNextKana(env);
-      return [silentMention(event, `次は「${next}」から❗`)];
+      const src = await getNextKanaSource(env);
+      const explanation = src !== undefined ? ` (「${src}」の読みから)` : "";
+      return [
+        silentMention(event, `次は${formatNextKana(next)}から❗${explanation}`),
+      ];
     },
   },
   {
@@ -50,7 +50,21 @@ export const publishToRelays = async (
 
 export const LAST_KANA_FILEPATH = "last_kana.txt";
 
-export const getNextKana = async (env: EnvVars): Promise<string> => {
+// returns candidates of kana that the next post should start with.
+// first line holds them (each of them is a sequence of kana in multi-mora mode), separated by "/".
+export const getNextKana = async (env: EnvVars): Promise<string[]> => {
   const t = await Deno.readTextFile(join(env.RESOURCE_DIR, LAST_KANA_FILEPATH));
-  return t.charAt(0);
+  return t.split("\n")[0].split("/");
+};
+
+// formats candidates of the next kana, like 「ン」か「ウ」
+export const formatNextKana = (cands: string[]): string =>
+  cands.map((k) => `「${k}」`).join("か");
+
+// returns the surface form of the word from which the next kana is derived, if recorded.
+export const getNextKanaSource = async (
+  env: EnvVars,
+): Promise<string | undefined> => {
+  const t = await Deno.readTextFile(join(env.RESOURCE_DIR, LAST_KANA_FILEPATH));
+  return t.split("\n")[2] || undefined;
 };
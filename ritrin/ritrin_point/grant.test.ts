@@ -409,6 +409,25 @@ Deno.test("grantSpecialConnectionPoint", async (t) => {
       }
     },
   );
+  await t.step(
+    "grant special-connection point if any pair of connected kana is special in multi-mora mode",
+    () => {
+      const lastSc = {
+        ...baseLastSc,
+        pubkey: "p1",
+        last: "ヲヴ",
+      };
+      const newScp = {
+        ...baseNewScp,
+        pubkey: "p2",
+        eventId: "e2",
+        head: "オブン",
+      };
+
+      const [pt] = grantSpecialConnectionPoint(lastSc, newScp);
+      assert(pt !== undefined, "pt should not be undefined");
+    },
+  );
   await t.step(
     "don't grant special-connection point if connection is not special",
     () => {
@@ -1,5 +1,6 @@
 import { jstTimeZone } from "../common.ts";
 import {
+  isLastUnchanged,
   LastShiritoriConnectionRecord,
   RitrinPointTransaction,
   ShiritoriConnectedPost,
@@ -153,7 +154,7 @@ export const grantHibernationBreakingPoint = (
     // grant hibernation-breaking point only if new event's author is different than prev event' author
     return [];
   }
-  if (newScp.head === newScp.last) {
+  if (isLastUnchanged(newScp)) {
     // grant hibernation-breaking point only if the last kana changed
     return [];
   }
@@ -216,10 +217,20 @@ export const grantNicePassPoint = (
 };
 
 /* bonus point for special shiritori connection  */
-const isSpecialConnection = (prevLast: string, newHead: string) =>
-  [["ヴ", "ブ"], ["ヲ", "オ"], ["ヰ", "イ"], ["ヱ", "エ"]].some(([pl, nh]) =>
-    pl === prevLast && nh === newHead
+// in multi-mora mode, prevLast and newHead are sequences of morae, and kana at the same positions are connected each other.
+// the connection is special if any pair of connected kana is special.
+const specialConnections = [
+  ["ヴ", "ブ"],
+  ["ヲ", "オ"],
+  ["ヰ", "イ"],
+  ["ヱ", "エ"],
+];
+const isSpecialConnection = (prevLast: string, newHead: string) => {
+  const nhs = Array.from(newHead);
+  return Array.from(prevLast).some((pl, i) =>
+    specialConnections.some(([spl, snh]) => spl === pl && snh === nhs[i])
   );
+};
 const specialConnectionPointAmount = 10;
 
 export const grantSpecialConnectionPoint = (
@@ -5,8 +5,11 @@ import { AppContext } from "../context.ts";
 import { grantRitrinPoints } from "./grant.ts";
 import {
   BonusPointType,
+  endsWithN,
   isBonusPoint,
+  isLastUnchanged,
   ShiritoriConnectedPost,
+  startsWithN,
 } from "./model.ts";
 import { RitrinPointTxRepo } from "./tx.ts";
 
@@ -78,14 +81,14 @@ export const launchShiritoriConnectionHook = (
 const shiritoriReactionContent = (
   newScp: ShiritoriConnectedPost,
 ): string => {
-  if (newScp.last === "ン") {
+  if (endsWithN(newScp.last)) {
     return "🤔";
   }
-  if (newScp.head === "ン") {
+  if (startsWithN(newScp.head)) {
     return "🥳";
   }
   // white: last kana not changed, red: last kana changed
-  return newScp.head === newScp.last ? "❕" : "❗";
+  return isLastUnchanged(newScp) ? "❕" : "❗";
 };
 
 export const handleShiritoriConnection = async (
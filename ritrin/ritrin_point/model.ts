@@ -6,6 +6,15 @@ export type ShiritoriConnectedPost = {
   acceptedAt: number;
 };
 
+// head and last of the post are single kana, or sequences of morae in multi-mora mode (e.g. "サ" + "ッカー").
+// in multi-mora mode, ン is grouped with the preceding kana, so it can be found only at the end of morae (or at the start of the whole reading).
+export const endsWithN = (kana: string) => kana.endsWith("ン");
+export const startsWithN = (kana: string) => kana.startsWith("ン");
+
+// checks if the post doesn't change the last of shiritori, i.e. the last of the post is the same as its head.
+export const isLastUnchanged = (scp: ShiritoriConnectedPost) =>
+  scp.head === scp.last;
+
 export type LastShiritoriConnectionRecord = ShiritoriConnectedPost & {
   hibernationBreaking: boolean;
 };
@@ -3,6 +3,7 @@ import * as log from "@std/log";
 import { basename } from "@std/path";
 import {
   currUnixtime,
+  formatNextKana,
   getNextKana,
   LAST_KANA_FILEPATH,
   publishToRelays,
@@ -14,7 +15,7 @@ const updateStatusOnNextKanaChange = (env: EnvVars, writeRelayUrls: string[]) =>
     const nextKana = await getNextKana(env);
     const k30315 = {
       kind: 30315,
-      content: `次は「${nextKana}」から！`,
+      content: `次は${formatNextKana(nextKana)}から！`,
       tags: [
         ["d", "general"],
         ["r", env.NOZOKIMADO_URL],
@@ -14,6 +14,7 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
@@ -26,6 +27,12 @@ var (
 	resourceDirPath string
 	yomiAPIBaseURL  string
 	reverseMode     bool
+	nounOnlyMode    bool
+	skipParticles   bool
+	tolerantMode    bool
+	customEmojiMode bool
+	yomiAPIV1Mode   bool
+	moraCount       = 1
 )
 
 var (
@@ -82,6 +89,18 @@ func initialize() error {
 		return errors.New("YOMI_API_BASE_URL is not set in .env")
 	}
 	_, reverseMode = os.LookupEnv("REVERSE_MODE")
+	_, nounOnlyMode = os.LookupEnv("NOUN_ONLY_MODE")
+	_, skipParticles = os.LookupEnv("SKIP_PARTICLES_MODE")
+	_, tolerantMode = os.LookupEnv("AMBIGUITY_TOLERANT_MODE")
+	_, customEmojiMode = os.LookupEnv("CUSTOM_EMOJI_MODE")
+	_, yomiAPIV1Mode = os.LookupEnv("YOMI_API_V1_MODE")
+	if mc := os.Getenv("MORA_COUNT"); mc != "" {
+		n, err := strconv.Atoi(mc)
+		if err != nil || n < 1 {
+			return errors.New("malformed MORA_COUNT")
+		}
+		moraCount = n
+	}
 
 	// add ritrin's pubkey to non-restricted pubkeys list
 	ritrinNsec := os.Getenv("RITRIN_PRIVATE_KEY")
@@ -176,20 +195,35 @@ func shiritoriSifter(input *strfrui.Input) (*strfrui.Result, error) {
 	}
 
 	// shiritori judgement
-	hl, err := getHeadLastKana(input.Event.Content)
+	readingHint := readingHintOf(input.Event)
+	hl, err := getHeadLastKana(input.Event.Content, readingHint, customEmojisOf(input.Event))
 	if err != nil {
 		log.Printf("failed to determine head/last of reading of content(%q): %v", input.Event.Content, err)
 		return input.Reject("blocked: couldn't determine head/last of reading of content")
 	}
+	if hl.HintError != "" {
+		log.Printf("rejecting reading hint %q for content(%q) from %s: %s", readingHint, input.Event.Content, input.Event.PubKey, hl.HintError)
+		return input.Reject("blocked: " + hl.HintError)
+	}
 	if !hl.Readable {
-		log.Printf("content(%q) is not readable", input.Event.Content)
-		return input.Reject("blocked: couldn't determine head/last of reading of content")
+		log.Printf("content(%q) is not readable (reason: %q)", input.Event.Content, hl.Reason)
+		return input.Reject(unreadableRejectMessage(hl.Reason))
+	}
+	if moraCount > 1 && (len(hl.HeadMorae) < moraCount || len(hl.LastMorae) < moraCount) {
+		log.Printf("reading of content(%q) is too short", input.Event.Content)
+		return input.Reject(fmt.Sprintf("blocked: reading of content is shorter than %d morae", moraCount))
+	}
+
+	// under nounOnlyMode, reject posts that don't end with a noun
+	if nounOnlyMode && !hl.endsWithNoun() {
+		log.Printf("content(%q) doesn't end with a noun (POS: %v)", input.Event.Content, hl.LastPOS)
+		return input.Reject("blocked: content must end with a noun (名詞)")
 	}
 
 	// swap head and last under reverseMode
 	nextHL := hl
 	if reverseMode {
-		nextHL = &HeadLastKanaResp{Readable: true, Head: hl.Last, Last: hl.Head}
+		nextHL = hl.reversed()
 	}
 	isShiritori, err := judgeShiritoriConnection(nextHL, input.Event)
 	if err != nil {
@@ -197,19 +231,24 @@ func shiritoriSifter(input *strfrui.Input) (*strfrui.Result, error) {
 		return nil, err
 	}
 	if !isShiritori {
-		log.Printf("❌Rejected! content: %s, head: %c, last: %c", strings.ReplaceAll(input.Event.Content, "\n", " "), nextHL.Head, nextHL.Last)
+		log.Printf("❌Rejected! content: %s, head: %s, last: %s", strings.ReplaceAll(input.Event.Content, "\n", " "), nextHL.headKana(), nextHL.lastKana())
 		return input.Reject("blocked: shiritori not connected")
 	}
 
+	if readingHint != "" {
+		// log accepted reading hints to review abuse
+		log.Printf("accepted reading hint %q for content(%q) from %s", readingHint, input.Event.Content, input.Event.PubKey)
+	}
+
 	// notify shiritori connection to ritrin
 	notifyShiritoriConnection(shiritoriConnectedPost{
 		Pubkey:     input.Event.PubKey,
 		EventID:    input.Event.ID,
-		Head:       string(nextHL.Head),
-		Last:       string(nextHL.Last),
+		Head:       nextHL.headKana(),
+		Last:       nextHL.lastKana(),
 		AcceptedAt: clock.Now().Unix(),
 	})
-	log.Printf("✅Accepted! content: %s, head: %c, last: %c", strings.ReplaceAll(input.Event.Content, "\n", " "), nextHL.Head, nextHL.Last)
+	log.Printf("✅Accepted! content: %s, head: %s, last: %s", strings.ReplaceAll(input.Event.Content, "\n", " "), nextHL.headKana(), nextHL.lastKana())
 	return input.Accept()
 }
 
@@ -226,18 +265,160 @@ func hasTagOfName(event *nostr.Event, name string) bool {
 	return false
 }
 
+// returns the reading of the content specified by the "reading" tag (e.g. ["reading", "ヨミ"]), if any.
+func readingHintOf(event *nostr.Event) string {
+	for _, tag := range event.Tags {
+		if len(tag) >= 2 && tag[0] == "reading" {
+			return tag[1]
+		}
+	}
+	return ""
+}
+
+// returns shortcodes of custom emoji declared in NIP-30 emoji tags (e.g. ["emoji", "wayo", "<url>"]).
+// in custom emoji mode, yomi-api reads only these shortcodes in the content.
+func customEmojisOf(event *nostr.Event) []string {
+	var scs []string
+	for _, tag := range event.Tags {
+		if len(tag) >= 2 && tag[0] == "emoji" {
+			scs = append(scs, tag[1])
+		}
+	}
+	return scs
+}
+
 type HeadLastKanaResp struct {
-	Readable bool `json:"readable"`
-	Head     rune `json:"head,omitempty"`
-	Last     rune `json:"last,omitempty"`
+	Readable  bool     `json:"readable"`
+	Head      rune     `json:"head,omitempty"`
+	Last      rune     `json:"last,omitempty"`
+	HeadMorae []string `json:"headMorae,omitempty"`
+	LastMorae []string `json:"lastMorae,omitempty"`
+	HeadPOS   []string `json:"headPos,omitempty"`
+	LastPOS   []string `json:"lastPos,omitempty"`
+
+	// surface form of tokens from which head/last are derived
+	HeadSurface string `json:"headSurface,omitempty"`
+	LastSurface string `json:"lastSurface,omitempty"`
+
+	// reason why the content is not readable
+	Reason string `json:"reason,omitempty"`
+	// reason why the reading hint is rejected
+	HintError string `json:"hintError,omitempty"`
+
+	// candidates of head/last kana considering ambiguity of reading
+	HeadCandidates []rune `json:"headCandidates,omitempty"`
+	LastCandidates []rune `json:"lastCandidates,omitempty"`
+}
+
+// messages for rejecting unreadable contents, keyed by reasons returned from yomi API
+var unreadableRejectMessages = map[string]string{
+	"empty":              "blocked: content is empty",
+	"onlyUrlsOrMentions": "blocked: content consists of only URLs or mentions",
+	"onlySymbols":        "blocked: content consists of only symbols or emoji",
+	"noKana":             "blocked: couldn't find any readable words in content",
+	"numberTooLong":      "blocked: content consists of a number too long to read",
+}
+
+func unreadableRejectMessage(reason string) string {
+	if msg, ok := unreadableRejectMessages[reason]; ok {
+		return msg
+	}
+	return "blocked: couldn't determine head/last of reading of content"
+}
+
+// returns a copy of the response whose head and last are swapped (for reverseMode).
+func (r *HeadLastKanaResp) reversed() *HeadLastKanaResp {
+	return &HeadLastKanaResp{
+		Readable:    r.Readable,
+		Head:        r.Last,
+		Last:        r.Head,
+		HeadMorae:   r.LastMorae,
+		LastMorae:   r.HeadMorae,
+		HeadPOS:     r.LastPOS,
+		LastPOS:     r.HeadPOS,
+		HeadSurface: r.LastSurface,
+		LastSurface: r.HeadSurface,
+
+		HeadCandidates: r.LastCandidates,
+		LastCandidates: r.HeadCandidates,
+	}
+}
+
+// checks if the last token of the content (from which last kana is derived) is a noun.
+func (r *HeadLastKanaResp) endsWithNoun() bool {
+	return len(r.LastPOS) != 0 && r.LastPOS[0] == "名詞"
+}
+
+// returns head of reading used for shiritori judgement.
+// in multi-mora mode, it is the concatenation of leading morae. otherwise, it is the single head kana.
+func (r *HeadLastKanaResp) headKana() string {
+	if len(r.HeadMorae) != 0 {
+		return strings.Join(r.HeadMorae, "")
+	}
+	return string(r.Head)
+}
+
+// returns all possible heads of reading. candidates are considered only in single-kana mode.
+func (r *HeadLastKanaResp) headKanaCandidates() []string {
+	if len(r.HeadMorae) != 0 || len(r.HeadCandidates) == 0 {
+		return []string{r.headKana()}
+	}
+	return runesToStrings(r.HeadCandidates)
+}
+
+// returns all possible lasts of reading. candidates are considered only in single-kana mode.
+func (r *HeadLastKanaResp) lastKanaCandidates() []string {
+	if len(r.LastMorae) != 0 || len(r.LastCandidates) == 0 {
+		return []string{r.lastKana()}
+	}
+	return runesToStrings(r.LastCandidates)
+}
+
+func runesToStrings(rs []rune) []string {
+	res := make([]string, 0, len(rs))
+	for _, r := range rs {
+		res = append(res, string(r))
+	}
+	return res
 }
 
-func getHeadLastKana(c string) (*HeadLastKanaResp, error) {
+// returns last of reading used for shiritori judgement.
+// in multi-mora mode, it is the concatenation of trailing morae. otherwise, it is the single last kana.
+func (r *HeadLastKanaResp) lastKana() string {
+	if len(r.LastMorae) != 0 {
+		return strings.Join(r.LastMorae, "")
+	}
+	return string(r.Last)
+}
+
+func getHeadLastKana(c string, readingHint string, customEmojis []string) (*HeadLastKanaResp, error) {
 	u, err := url.Parse(yomiAPIBaseURL)
 	if err != nil {
 		return nil, err
 	}
+	// the legacy endpoint keeps the old readings. /v1/ reads numbers with counters, units, symbols, emoji, romaji etc. by default
+	if yomiAPIV1Mode {
+		u = u.JoinPath("v1", "analyze")
+	}
 	qv := url.Values{"c": []string{c}}
+	if moraCount > 1 {
+		qv.Set("n", strconv.Itoa(moraCount))
+	}
+	if skipParticles {
+		qv.Set("skipParticles", "true")
+	}
+	if readingHint != "" {
+		qv.Set("reading", readingHint)
+	}
+	if tolerantMode {
+		qv.Set("candidates", "true")
+	}
+	if customEmojiMode {
+		qv.Set("customEmoji", "true")
+		for _, sc := range customEmojis {
+			qv.Add("emojiTag", sc)
+		}
+	}
 	u.RawQuery = qv.Encode()
 
 	resp, err := http.Get(u.String())
@@ -344,8 +525,53 @@ var allowedConnections = map[rune][]rune{
 	'ヶ': {'ケ'},
 }
 
+// small kana that form a single mora together with the preceding kana (拗音).
+var smallKanaForYoon = map[rune]struct{}{
+	'ァ': {},
+	'ィ': {},
+	'ゥ': {},
+	'ェ': {},
+	'ォ': {},
+	'ャ': {},
+	'ュ': {},
+	'ョ': {},
+	'ヮ': {},
+}
+
+// split kana sequence into morae. small kana that form 拗音, long vowel marks (ー) and ン are grouped with the preceding kana,
+// and ッ is grouped with the following kana (or the preceding kana if it is at the end).
+// must be consistent with the one in yomi-api.
+func splitMorae(s string) [][]rune {
+	morae := make([][]rune, 0, len(s)/3)
+	var sokuon []rune
+	for _, r := range s {
+		_, yoon := smallKanaForYoon[r]
+		switch {
+		case r == 'ッ' || len(sokuon) != 0 && (yoon || r == 'ー' || r == 'ン'):
+			sokuon = append(sokuon, r)
+		case len(sokuon) != 0:
+			morae = append(morae, append(sokuon, r))
+			sokuon = nil
+		case (yoon || r == 'ー' || r == 'ン') && len(morae) > 0:
+			morae[len(morae)-1] = append(morae[len(morae)-1], r)
+		case r == 'ー':
+			continue
+		default:
+			morae = append(morae, []rune{r})
+		}
+	}
+	if len(sokuon) != 0 {
+		if len(morae) > 0 {
+			morae[len(morae)-1] = append(morae[len(morae)-1], sokuon...)
+		} else {
+			morae = append(morae, sokuon)
+		}
+	}
+	return morae
+}
+
 // pre-condition: prevLast and currHead are normalized to fullwidth katakana
-func isShiritoriConnected(prevLast, currHead rune) bool {
+func isKanaConnected(prevLast, currHead rune) bool {
 	if prevLast == currHead {
 		return true
 	}
@@ -359,9 +585,55 @@ func isShiritoriConnected(prevLast, currHead rune) bool {
 	return false
 }
 
+// checks if currHead follows prevLast, comparing each mora with allowed connections of kana.
+//
+// if prevLast is shorter than currHead (i.e. the reading of the previous post has fewer morae than MORA_COUNT), only leading morae of currHead are compared.
+// note that prevLast derived with another MORA_COUNT can't be compared, so the state is reset when MORA_COUNT is changed (see judgeShiritoriConnection).
+//
+// pre-condition: prevLast and currHead are normalized to fullwidth katakana
+func isShiritoriConnected(prevLast, currHead string) bool {
+	pms, cms := splitMorae(prevLast), splitMorae(currHead)
+	if len(pms) == 0 || len(pms) > len(cms) {
+		return false
+	}
+	for i, pm := range pms {
+		cm := cms[i]
+		if len(pm) != len(cm) {
+			return false
+		}
+		for j := range pm {
+			if !isKanaConnected(pm[j], cm[j]) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// separator of candidates of last kana in the state file
+const lastKanaCandidatesSep = "/"
+
+// checks if any of candidates of current head follows any of candidates of previous last.
+func isAnyShiritoriConnected(prevLasts, currHeads []string) bool {
+	for _, pl := range prevLasts {
+		for _, ch := range currHeads {
+			if isShiritoriConnected(pl, ch) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 type lastKanaData struct {
-	lastKana rune
-	eventID  string
+	// sequences of kana that the next post should start with (single kana unless multi-mora mode).
+	// there are multiple candidates if the reading of the previous post is ambiguous.
+	lastKanas []string
+	eventID   string
+	// surface form of the token from which lastKanas are derived. used by ritrin to explain the next kana.
+	lastSurface string
+	// number of morae (MORA_COUNT) with which lastKanas are derived. 1 if not recorded (saved before multi-mora mode).
+	moraCount int
 }
 
 func loadLastKana(r io.Reader) (*lastKanaData, error) {
@@ -374,12 +646,24 @@ func loadLastKana(r io.Reader) (*lastKanaData, error) {
 		return nil, nil
 	}
 	lines := strings.Split(string(b), "\n")
-	lastKana := []rune(lines[0])[0]
+	lastKanas := strings.Split(lines[0], lastKanaCandidatesSep)
 	eventID := ""
 	if len(lines) > 1 {
 		eventID = lines[1]
 	}
-	return &lastKanaData{lastKana, eventID}, nil
+	lastSurface := ""
+	if len(lines) > 2 {
+		lastSurface = lines[2]
+	}
+	mc := 1
+	if len(lines) > 3 {
+		n, err := strconv.Atoi(lines[3])
+		if err != nil || n < 1 {
+			return nil, fmt.Errorf("malformed mora count in last kana data: %q", lines[3])
+		}
+		mc = n
+	}
+	return &lastKanaData{lastKanas, eventID, lastSurface, mc}, nil
 }
 
 func saveLastKana(f *os.File, d *lastKanaData) error {
@@ -389,7 +673,7 @@ func saveLastKana(f *os.File, d *lastKanaData) error {
 	if _, err := f.Seek(0, io.SeekStart); err != nil {
 		return err
 	}
-	if _, err := fmt.Fprintf(f, "%c\n%s", d.lastKana, d.eventID); err != nil {
+	if _, err := fmt.Fprintf(f, "%s\n%s\n%s\n%d", strings.Join(d.lastKanas, lastKanaCandidatesSep), d.eventID, d.lastSurface, d.moraCount); err != nil {
 		return err
 	}
 	return nil
@@ -420,18 +704,23 @@ func judgeShiritoriConnection(hl *HeadLastKanaResp, ev *nostr.Event) (bool, erro
 		return false, err
 	}
 
+	if prev != nil && prev.moraCount != moraCount {
+		// lasts derived with another number of morae can't be compared with heads, so start over
+		log.Printf("MORA_COUNT has been changed from %d to %d; resetting the last kana", prev.moraCount, moraCount)
+		prev = nil
+	}
 	if prev != nil {
 		if ev.ID == prev.eventID {
 			// reject same event
 			return false, nil
 		}
-		if !isShiritoriConnected(prev.lastKana, hl.Head) {
+		if !isAnyShiritoriConnected(prev.lastKanas, hl.headKanaCandidates()) {
 			return false, nil
 		}
 	}
 
 	// no prev (first event) or shiritori connected
-	if err := saveLastKana(fl.f, &lastKanaData{hl.Last, ev.ID}); err != nil {
+	if err := saveLastKana(fl.f, &lastKanaData{hl.lastKanaCandidates(), ev.ID, hl.LastSurface, moraCount}); err != nil {
 		return false, err
 	}
 	return true, nil
@@ -1,6 +1,12 @@
 package main
 
 import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 
@@ -72,3 +78,237 @@ func TestShiritoriSifter_basic(t *testing.T) {
 		}
 	}
 }
+
+func TestIsShiritoriConnected(t *testing.T) {
+	tests := []struct {
+		prevLast string
+		currHead string
+		want     bool
+	}{
+		{prevLast: "ア", currHead: "ア", want: true},
+		{prevLast: "ア", currHead: "イ", want: false},
+		{prevLast: "ガ", currHead: "カ", want: true},
+		{prevLast: "カ", currHead: "ガ", want: false},
+		{prevLast: "ョ", currHead: "ヨ", want: true},
+		{prevLast: "ヴ", currHead: "ブ", want: true},
+		{prevLast: "シン", currHead: "シン", want: true},
+		{prevLast: "ジン", currHead: "シン", want: true},
+		{prevLast: "シン", currHead: "ジン", want: false},
+		{prevLast: "ジャク", currHead: "シャク", want: true},
+		{prevLast: "ジャク", currHead: "シヤク", want: false},
+		{prevLast: "キャ", currHead: "キヤ", want: false},
+		{prevLast: "ア", currHead: "アイ", want: true},
+		{prevLast: "サッカー", currHead: "サッカー", want: true},
+		{prevLast: "ヒー", currHead: "ビー", want: false},
+		{prevLast: "ビー", currHead: "ヒー", want: true},
+		{prevLast: "ッカー", currHead: "ッカ", want: false},
+		{prevLast: "カン", currHead: "カ", want: false},
+		{prevLast: "アイ", currHead: "ア", want: false},
+		{prevLast: "", currHead: "ア", want: false},
+	}
+
+	for _, tt := range tests {
+		if got := isShiritoriConnected(tt.prevLast, tt.currHead); got != tt.want {
+			t.Errorf("isShiritoriConnected(%q, %q) = %v; want %v", tt.prevLast, tt.currHead, got, tt.want)
+		}
+	}
+}
+
+func TestHeadLastKanaResp_endsWithNoun(t *testing.T) {
+	tests := []struct {
+		lastPOS []string
+		want    bool
+	}{
+		{lastPOS: []string{"名詞", "一般", "*", "*"}, want: true},
+		{lastPOS: []string{"名詞", "固有名詞", "人名", "名"}, want: true},
+		{lastPOS: []string{"動詞", "自立", "*", "*"}, want: false},
+		{lastPOS: []string{"助詞", "終助詞", "*", "*"}, want: false},
+		{lastPOS: nil, want: false},
+	}
+
+	for _, tt := range tests {
+		r := &HeadLastKanaResp{Readable: true, LastPOS: tt.lastPOS}
+		if got := r.endsWithNoun(); got != tt.want {
+			t.Errorf("endsWithNoun() with lastPOS %v = %v; want %v", tt.lastPOS, got, tt.want)
+		}
+	}
+}
+
+func TestLoadLastKana(t *testing.T) {
+	tests := []struct {
+		in   string
+		want *lastKanaData
+	}{
+		{in: "", want: nil},
+		{in: "ア\nid", want: &lastKanaData{lastKanas: []string{"ア"}, eventID: "id", moraCount: 1}},
+		{in: "シン\nid", want: &lastKanaData{lastKanas: []string{"シン"}, eventID: "id", moraCount: 1}},
+		{in: "シ\nid\n寿司", want: &lastKanaData{lastKanas: []string{"シ"}, eventID: "id", lastSurface: "寿司", moraCount: 1}},
+		{in: "ン/ウ\nid", want: &lastKanaData{lastKanas: []string{"ン", "ウ"}, eventID: "id", moraCount: 1}},
+		{in: "シン\nid\n\n2", want: &lastKanaData{lastKanas: []string{"シン"}, eventID: "id", moraCount: 2}},
+		{in: "シン\nid\n進\n2", want: &lastKanaData{lastKanas: []string{"シン"}, eventID: "id", lastSurface: "進", moraCount: 2}},
+	}
+
+	for _, tt := range tests {
+		got, err := loadLastKana(strings.NewReader(tt.in))
+		if err != nil {
+			t.Errorf("loadLastKana(%q) got unexpected error: %v", tt.in, err)
+			continue
+		}
+		if tt.want == nil {
+			if got != nil {
+				t.Errorf("loadLastKana(%q) = %+v; want nil", tt.in, got)
+			}
+			continue
+		}
+		if got == nil || !slices.Equal(got.lastKanas, tt.want.lastKanas) || got.eventID != tt.want.eventID || got.lastSurface != tt.want.lastSurface || got.moraCount != tt.want.moraCount {
+			t.Errorf("loadLastKana(%q) = %+v; want %+v", tt.in, got, tt.want)
+		}
+	}
+}
+
+// the last kana derived with another MORA_COUNT is discarded, so that the next post is accepted regardless of its head.
+func TestJudgeShiritoriConnection_moraCountChanged(t *testing.T) {
+	origDir, origMoraCount := resourceDirPath, moraCount
+	defer func() { resourceDirPath, moraCount = origDir, origMoraCount }()
+	resourceDirPath = t.TempDir()
+	path := filepath.Join(resourceDirPath, "last_kana.txt")
+
+	tests := []struct {
+		saved     string
+		moraCount int
+		want      bool
+	}{
+		{saved: "シン\nprev\n\n2", moraCount: 1, want: true},
+		{saved: "シン\nprev\n\n2", moraCount: 2, want: false},
+		{saved: "シ\nprev", moraCount: 1, want: true},
+		{saved: "ア\nprev", moraCount: 1, want: false},
+	}
+	for _, tt := range tests {
+		if err := os.WriteFile(path, []byte(tt.saved), 0666); err != nil {
+			t.Fatal(err)
+		}
+		moraCount = tt.moraCount
+		got, err := judgeShiritoriConnection(&HeadLastKanaResp{Readable: true, Head: 'シ', Last: 'リ'}, &nostr.Event{ID: "curr"})
+		if err != nil {
+			t.Fatalf("judgeShiritoriConnection got unexpected error: %v", err)
+		}
+		if got != tt.want {
+			t.Errorf("judgeShiritoriConnection with saved %q and MORA_COUNT %d = %v; want %v", tt.saved, tt.moraCount, got, tt.want)
+		}
+		if got {
+			b, _ := os.ReadFile(path)
+			if d, _ := loadLastKana(strings.NewReader(string(b))); d == nil || d.moraCount != tt.moraCount || d.eventID != "curr" {
+				t.Errorf("saved last kana data = %+v; want one with mora count %d", d, tt.moraCount)
+			}
+		}
+	}
+}
+
+func TestReadingHintOf(t *testing.T) {
+	tests := []struct {
+		tags nostr.Tags
+		want string
+	}{
+		{tags: nostr.Tags{}, want: ""},
+		{tags: nostr.Tags{{"reading", "ニッポン"}}, want: "ニッポン"},
+		{tags: nostr.Tags{{"t", "shiritori"}, {"reading", "ニッポン"}}, want: "ニッポン"},
+		{tags: nostr.Tags{{"reading"}}, want: ""},
+	}
+
+	for _, tt := range tests {
+		ev := testEvent(func(ev *nostr.Event) { ev.Tags = tt.tags })
+		if got := readingHintOf(ev); got != tt.want {
+			t.Errorf("readingHintOf(%v) = %q; want %q", tt.tags, got, tt.want)
+		}
+	}
+}
+
+func TestCustomEmojisOf(t *testing.T) {
+	tests := []struct {
+		tags nostr.Tags
+		want []string
+	}{
+		{tags: nostr.Tags{}, want: nil},
+		{tags: nostr.Tags{{"emoji", "wayo", "https://example.com/wayo.png"}}, want: []string{"wayo"}},
+		{tags: nostr.Tags{{"t", "shiritori"}, {"emoji", "wayo", "https://example.com/wayo.png"}, {"emoji", "pizza", "https://example.com/pizza.png"}}, want: []string{"wayo", "pizza"}},
+		{tags: nostr.Tags{{"emoji"}}, want: nil},
+	}
+
+	for _, tt := range tests {
+		ev := testEvent(func(ev *nostr.Event) { ev.Tags = tt.tags })
+		if got := customEmojisOf(ev); !slices.Equal(got, tt.want) {
+			t.Errorf("customEmojisOf(%v) = %q; want %q", tt.tags, got, tt.want)
+		}
+	}
+}
+
+func TestIsAnyShiritoriConnected(t *testing.T) {
+	tests := []struct {
+		prevLasts []string
+		currHeads []string
+		want      bool
+	}{
+		{prevLasts: []string{"ン"}, currHeads: []string{"ン"}, want: true},
+		{prevLasts: []string{"ン", "ウ"}, currHeads: []string{"ウ"}, want: true},
+		{prevLasts: []string{"ン"}, currHeads: []string{"キ", "コ"}, want: false},
+		{prevLasts: []string{"ウ", "チ"}, currHeads: []string{"キ", "コ", "チ"}, want: true},
+		{prevLasts: []string{"ズ", "テ"}, currHeads: []string{"ス"}, want: true},
+	}
+
+	for _, tt := range tests {
+		if got := isAnyShiritoriConnected(tt.prevLasts, tt.currHeads); got != tt.want {
+			t.Errorf("isAnyShiritoriConnected(%q, %q) = %v; want %v", tt.prevLasts, tt.currHeads, got, tt.want)
+		}
+	}
+}
+
+func TestUnreadableRejectMessage(t *testing.T) {
+	tests := []struct {
+		reason string
+		want   string
+	}{
+		{reason: "empty", want: "blocked: content is empty"},
+		{reason: "onlyUrlsOrMentions", want: "blocked: content consists of only URLs or mentions"},
+		{reason: "onlySymbols", want: "blocked: content consists of only symbols or emoji"},
+		{reason: "numberTooLong", want: "blocked: content consists of a number too long to read"},
+		{reason: "noKana", want: "blocked: couldn't find any readable words in content"},
+		{reason: "", want: "blocked: couldn't determine head/last of reading of content"},
+	}
+
+	for _, tt := range tests {
+		if got := unreadableRejectMessage(tt.reason); got != tt.want {
+			t.Errorf("unreadableRejectMessage(%q) = %q; want %q", tt.reason, got, tt.want)
+		}
+	}
+}
+
+func TestGetHeadLastKana_yomiAPIV1Mode(t *testing.T) {
+	var gotPath string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		_, _ = w.Write([]byte(`{"readable":true,"head":12522,"last":12468}`))
+	}))
+	defer srv.Close()
+
+	origBaseURL, origV1Mode := yomiAPIBaseURL, yomiAPIV1Mode
+	defer func() { yomiAPIBaseURL, yomiAPIV1Mode = origBaseURL, origV1Mode }()
+	yomiAPIBaseURL = srv.URL
+
+	tests := []struct {
+		v1Mode bool
+		want   string
+	}{
+		{v1Mode: false, want: "/"},
+		{v1Mode: true, want: "/v1/analyze"},
+	}
+	for _, tt := range tests {
+		yomiAPIV1Mode = tt.v1Mode
+		gotPath = ""
+		if _, err := getHeadLastKana("りんご", "", nil); err != nil {
+			t.Fatalf("getHeadLastKana got unexpected error: %v", err)
+		}
+		if gotPath != tt.want {
+			t.Errorf("getHeadLastKana with v1 mode %v requested %q; want %q", tt.v1Mode, gotPath, tt.want)
+		}
+	}
+}
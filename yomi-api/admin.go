@@ -0,0 +1,122 @@
+package main
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+)
+
+// token required for admin endpoints. admin endpoints are disabled if empty.
+var adminToken string
+
+// entry of extra dictionary in request body of admin endpoint.
+type adminDictEntry struct {
+	Word    string `json:"word"`
+	Reading string `json:"reading"`
+}
+
+// manages entries of extra dictionaries.
+// requests must have "Authorization: Bearer <token>" header, and kind of dictionary ("reading", "replace" or "symbol") must be specified by the query parameter "kind".
+//
+//   - GET: lists entries
+//   - POST: adds (or overwrites) the entry specified by the request body ({"word": "...", "reading": "..."})
+//   - DELETE: removes the entry specified by the query parameter "word"
+func handleAdminDict(w http.ResponseWriter, r *http.Request) {
+	if !isAuthorizedAdmin(r) {
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = fmt.Fprintf(w, "unauthorized")
+		return
+	}
+
+	kind := dictKind(r.URL.Query().Get("kind"))
+	if _, ok := extraDictFileNames[kind]; !ok {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = fmt.Fprintf(w, "invalid kind")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		jenc := json.NewEncoder(w)
+		jenc.SetIndent("", "")
+		_ = jenc.Encode(listExtraDictEntries(kind))
+
+	case http.MethodPost:
+		var e adminDictEntry
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 4096)).Decode(&e); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = fmt.Fprintf(w, "malformed request body: %v", err)
+			return
+		}
+		word, reading, err := normalizeExtraDictEntry(kind, e.Word, e.Reading)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = fmt.Fprint(w, err.Error())
+			return
+		}
+		if err := addExtraDictEntry(kind, word, reading); err != nil {
+			log.Printf("failed to add entry to extra %s dictionary: %v", kind, err)
+			w.WriteHeader(http.StatusInternalServerError)
+			_, _ = fmt.Fprintf(w, "failed to update dictionary")
+			return
+		}
+		log.Printf("added entry to extra %s dictionary: %s %s", kind, word, reading)
+		_, _ = fmt.Fprintf(w, "ok")
+
+	case http.MethodDelete:
+		word := r.URL.Query().Get("word")
+		ok, err := removeExtraDictEntry(kind, word)
+		if err != nil {
+			log.Printf("failed to remove entry from extra %s dictionary: %v", kind, err)
+			w.WriteHeader(http.StatusInternalServerError)
+			_, _ = fmt.Fprintf(w, "failed to update dictionary")
+			return
+		}
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = fmt.Fprintf(w, "no such entry")
+			return
+		}
+		log.Printf("removed entry from extra %s dictionary: %s", kind, word)
+		_, _ = fmt.Fprintf(w, "ok")
+
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		_, _ = fmt.Fprintf(w, "method not allowed")
+	}
+}
+
+func isAuthorizedAdmin(r *http.Request) bool {
+	if adminToken == "" {
+		return false
+	}
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return ok && subtle.ConstantTimeCompare([]byte(token), []byte(adminToken)) == 1
+}
+
+// reloads the user dictionary from the file.
+// requests must have "Authorization: Bearer <token>" header.
+func handleAdminReloadUserDict(w http.ResponseWriter, r *http.Request) {
+	if !isAuthorizedAdmin(r) {
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = fmt.Fprintf(w, "unauthorized")
+		return
+	}
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		_, _ = fmt.Fprintf(w, "method not allowed")
+		return
+	}
+
+	if err := reloadUserDict(); err != nil {
+		log.Printf("failed to reload user dictionary: %v", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = fmt.Fprintf(w, "failed to reload user dictionary: %v", err)
+		return
+	}
+	log.Print("reloaded user dictionary")
+	_, _ = fmt.Fprintf(w, "ok")
+}
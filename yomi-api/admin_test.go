@@ -0,0 +1,70 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHandleAdminDict(t *testing.T) {
+	if err := initialize(); err != nil {
+		log.Fatal(err)
+	}
+	restoreExtraDictsOnCleanup(t)
+	if err := loadExtraDicts(t.TempDir()); err != nil {
+		t.Fatalf("loadExtraDicts returned error: %v", err)
+	}
+	adminToken = "secret"
+	t.Cleanup(func() { adminToken = "" })
+
+	do := func(method, target, token, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		handleAdminDict(rec, req)
+		return rec
+	}
+
+	tests := []struct {
+		method string
+		target string
+		token  string
+		body   string
+		want   int
+	}{
+		{method: http.MethodGet, target: "/admin/dict?kind=reading", want: http.StatusUnauthorized},
+		{method: http.MethodGet, target: "/admin/dict?kind=reading", token: "wrong", want: http.StatusUnauthorized},
+		{method: http.MethodGet, target: "/admin/dict?kind=unknown", token: "secret", want: http.StatusBadRequest},
+		{method: http.MethodPost, target: "/admin/dict?kind=reading", token: "secret", body: `{"word": "zorblax", "reading": "ゾルブラックス"}`, want: http.StatusOK},
+		{method: http.MethodPost, target: "/admin/dict?kind=reading", token: "secret", body: `{"word": "zorblax", "reading": "zorblax"}`, want: http.StatusBadRequest},
+		{method: http.MethodPost, target: "/admin/dict?kind=reading", token: "secret", body: `not json`, want: http.StatusBadRequest},
+		{method: http.MethodPost, target: "/admin/dict?kind=replace", token: "secret", body: `{"word": "#tag", "reading": "タグ"}`, want: http.StatusBadRequest},
+		{method: http.MethodPut, target: "/admin/dict?kind=reading", token: "secret", want: http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		if rec := do(tt.method, tt.target, tt.token, tt.body); rec.Code != tt.want {
+			t.Errorf("%s %s = %d; want %d", tt.method, tt.target, rec.Code, tt.want)
+		}
+	}
+
+	rec := do(http.MethodGet, "/admin/dict?kind=reading", "secret", "")
+	var entries map[string]string
+	if err := json.NewDecoder(rec.Body).Decode(&entries); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if entries["ZORBLAX"] != "ゾルブラックス" {
+		t.Errorf("listed entries = %v; want added entry", entries)
+	}
+
+	if rec := do(http.MethodDelete, "/admin/dict?kind=reading&word=zorblax", "secret", ""); rec.Code != http.StatusOK {
+		t.Errorf("DELETE = %d; want %d", rec.Code, http.StatusOK)
+	}
+	if rec := do(http.MethodDelete, "/admin/dict?kind=reading&word=zorblax", "secret", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("DELETE of removed entry = %d; want %d", rec.Code, http.StatusNotFound)
+	}
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"runtime"
+	"sync"
+)
+
+const (
+	maxBatchItems    = 10000
+	maxBatchBodySize = 32 << 20 // 32MiB
+)
+
+// an item of batch analysis request. it is either a content string or a Nostr event.
+type analyzeBatchItem struct {
+	Content string
+	// tags of the event. nil if the item is a content string.
+	Tags [][]string
+}
+
+func (i *analyzeBatchItem) UnmarshalJSON(b []byte) error {
+	b = bytes.TrimSpace(b)
+	if len(b) > 0 && b[0] == '"' {
+		return json.Unmarshal(b, &i.Content)
+	}
+
+	var ev struct {
+		Content *string    `json:"content"`
+		Tags    [][]string `json:"tags"`
+	}
+	if err := json.Unmarshal(b, &ev); err != nil {
+		return err
+	}
+	if ev.Content == nil {
+		return errors.New("event must have content")
+	}
+	i.Content = *ev.Content
+	i.Tags = ev.Tags
+	return nil
+}
+
+// returns the reading specified by the "reading" tag of the event, if any.
+func (i *analyzeBatchItem) readingHint() string {
+	for _, tag := range i.Tags {
+		if len(tag) >= 2 && tag[0] == "reading" {
+			return tag[1]
+		}
+	}
+	return ""
+}
+
+// returns shortcodes of custom emoji declared in NIP-30 emoji tags of the event (e.g. ["emoji", "wayo", "<url>"]).
+func (i *analyzeBatchItem) customEmojis() []string {
+	var scs []string
+	for _, tag := range i.Tags {
+		if len(tag) >= 2 && tag[0] == "emoji" {
+			scs = append(scs, tag[1])
+		}
+	}
+	return scs
+}
+
+// analyzes multiple contents (or Nostr events) at once.
+// request body must be a JSON array of contents or Nostr events, and results are returned in the same order.
+// options for analysis are specified by query parameters, just like GET /v1/analyze.
+func handleAnalyzeBatch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		_, _ = fmt.Fprintf(w, "method not allowed")
+		return
+	}
+
+	opts, err := parseV1AnalyzeOptions(r.URL.Query())
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = fmt.Fprint(w, err.Error())
+		return
+	}
+
+	var items []analyzeBatchItem
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBatchBodySize)).Decode(&items); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = fmt.Fprintf(w, "malformed request body: %v", err)
+		return
+	}
+	if len(items) > maxBatchItems {
+		w.WriteHeader(http.StatusRequestEntityTooLarge)
+		_, _ = fmt.Fprintf(w, "too many items (max: %d)", maxBatchItems)
+		return
+	}
+
+	resps := analyzeBatch(items, opts)
+
+	jenc := json.NewEncoder(w)
+	jenc.SetIndent("", "")
+	_ = jenc.Encode(resps)
+}
+
+// analyzes items concurrently. results are in the same order as items.
+func analyzeBatch(items []analyzeBatchItem, opts analyzeOptions) []HeadLastKanaResp {
+	resps := make([]HeadLastKanaResp, len(items))
+	idxCh := make(chan int)
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		failures int
+	)
+	for range min(runtime.NumCPU(), len(items)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range idxCh {
+				itemOpts := opts
+				itemOpts.readingHint = items[i].readingHint()
+				itemOpts.customEmojis = items[i].customEmojis()
+
+				resp, err := headLastKanaResp(items[i].Content, itemOpts)
+				if err != nil {
+					mu.Lock()
+					failures++
+					mu.Unlock()
+				}
+				resps[i] = resp
+			}
+		}()
+	}
+	for i := range items {
+		idxCh <- i
+	}
+	close(idxCh)
+	wg.Wait()
+
+	log.Printf("analyzed %d items in batch (%d not readable)", len(items), failures)
+	return resps
+}
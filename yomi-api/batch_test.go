@@ -0,0 +1,87 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHandleAnalyzeBatch(t *testing.T) {
+	if err := initialize(); err != nil {
+		log.Fatal(err)
+	}
+
+	body := `[
+		"あいうえお",
+		{"kind": 1, "content": "日本", "tags": [["reading", "ニッポン"]]},
+		"！？",
+		{"content": "日本", "tags": [["reading", "ヤマト"]]},
+		"漢字",
+		{"content": ":cat:", "tags": [["emoji", "cat", "https://example.com/cat.png"]]},
+		":cat:"
+	]`
+	req := httptest.NewRequest(http.MethodPost, "/v1/analyze?customEmoji=true", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	handleAnalyzeBatch(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d; want %d", rec.Code, http.StatusOK)
+	}
+	var got []HeadLastKanaResp
+	if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	want := []struct {
+		readable bool
+		head     rune
+		last     rune
+		hintErr  bool
+	}{
+		{readable: true, head: 'ア', last: 'オ'},
+		{readable: true, head: 'ニ', last: 'ン'},
+		{readable: false},
+		{readable: false, hintErr: true},
+		{readable: true, head: 'カ', last: 'ジ'},
+		{readable: true, head: 'キ', last: 'ト'},
+		{readable: false},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("len(results) = %d; want %d", len(got), len(want))
+	}
+	for i, w := range want {
+		g := got[i]
+		if g.Readable != w.readable || g.Head != w.head || g.Last != w.last || (g.HintError != "") != w.hintErr {
+			t.Errorf("results[%d] = %+v; want %+v", i, g, w)
+		}
+	}
+}
+
+func TestHandleAnalyzeBatch_badRequest(t *testing.T) {
+	tests := []struct {
+		method string
+		query  string
+		body   string
+		want   int
+	}{
+		{method: http.MethodGet, body: `[]`, want: http.StatusMethodNotAllowed},
+		{method: http.MethodPost, body: `{"content": "あ"}`, want: http.StatusBadRequest},
+		{method: http.MethodPost, body: `[{"kind": 1}]`, want: http.StatusBadRequest},
+		{method: http.MethodPost, body: `[1]`, want: http.StatusBadRequest},
+		{method: http.MethodPost, query: "?n=0", body: `[]`, want: http.StatusBadRequest},
+		{method: http.MethodPost, body: `[` + strings.Repeat(`"あ",`, maxBatchItems) + `"あ"]`, want: http.StatusRequestEntityTooLarge},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, "/v1/analyze"+tt.query, strings.NewReader(tt.body))
+		rec := httptest.NewRecorder()
+		handleAnalyzeBatch(rec, req)
+
+		if rec.Code != tt.want {
+			t.Errorf("%s /v1/analyze%s with body(len: %d) status = %d; want %d", tt.method, tt.query, len(tt.body), rec.Code, tt.want)
+		}
+	}
+}
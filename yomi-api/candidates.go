@@ -0,0 +1,62 @@
+package main
+
+import (
+	"slices"
+
+	"github.com/ikawaha/kagome/v2/tokenizer"
+)
+
+// returns candidates of head/last kana of the text, taking ambiguity of reading into account.
+// the first elements are always the head/last kana of the best reading.
+//
+// candidates are collected from:
+//   - alternate readings of the head/last token in the dictionary (e.g. 日本: ニホン/ニッポン)
+//   - head/last of another path of the lattice, which is obtained by analyzing in another tokenize mode (usually the search mode).
+//     kagome doesn't expose N-best paths, so this is used as an approximation of them.
+//
+// if the reading hint is given, the hinted reading is only the candidate.
+func (hl *headLastResult) candidates(opts analyzeOptions) ([]rune, []rune) {
+	heads := []rune{hl.head}
+	lasts := []rune{hl.last}
+	if hl.hintedReadings != nil {
+		return heads, lasts
+	}
+
+	addHead := func(k rune) {
+		if k != 0 && !slices.Contains(heads, k) {
+			heads = append(heads, k)
+		}
+	}
+	addLast := func(k rune) {
+		if k != 0 && !slices.Contains(lasts, k) {
+			lasts = append(lasts, k)
+		}
+	}
+	addFromTokens := func(headTok, lastTok tokenizer.Token) {
+		for _, r := range alternateReadingsOfToken(headTok) {
+			addHead(headKana(r))
+		}
+		for _, r := range alternateReadingsOfToken(lastTok) {
+			addLast(lastKana(r))
+		}
+	}
+
+	addFromTokens(hl.headToken(), hl.lastToken())
+
+	alt, err := pickHeadAndLast(currentTokenizer().Analyze(hl.normalized, alternateTokenizeMode()), nil, opts)
+	if err == nil {
+		addHead(alt.head)
+		addLast(alt.last)
+		addFromTokens(alt.headToken(), alt.lastToken())
+	}
+	return heads, lasts
+}
+
+// returns alternate readings of the token in the dictionary, which are of entries of the same part of speech as the token.
+// tokens which are read as is (kana, English words and words in the user dictionary) have no alternate readings.
+func alternateReadingsOfToken(t tokenizer.Token) []string {
+	if isUserDictToken(t) || regexpFwKanaWord.MatchString(t.Surface) || regexpAllHwKana.MatchString(t.Surface) || regexpAllEnAlphabet.MatchString(t.Surface) {
+		return nil
+	}
+	return dictReadingsOf(t.Surface, t.POS())
+}
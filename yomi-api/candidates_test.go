@@ -0,0 +1,66 @@
+package main
+
+import (
+	"log"
+	"slices"
+	"testing"
+)
+
+func TestHeadLastResultCandidates(t *testing.T) {
+	if err := initialize(); err != nil {
+		log.Fatal(err)
+	}
+
+	tests := []struct {
+		in       string
+		opts     analyzeOptions
+		inHeads  []rune
+		inLasts  []rune
+		notHeads []rune
+		notLasts []rune
+	}{
+		{in: "日本", inHeads: []rune{'ニ'}, inLasts: []rune{'ン'}},
+		{in: "上手", inHeads: []rune{'ジ', 'カ', 'ウ'}, inLasts: []rune{'ズ', 'テ'}},
+		{in: "今日", inHeads: []rune{'キ', 'コ'}, inLasts: []rune{'ウ', 'チ'}},
+		{in: "今日は上手", inHeads: []rune{'キ', 'コ'}, inLasts: []rune{'ズ', 'テ'}},
+		{in: "ねこ", inHeads: []rune{'ネ'}, inLasts: []rune{'コ'}, notHeads: []rune{'ニ'}},
+		{in: "上手", opts: analyzeOptions{readingHint: "カミテ"}, inHeads: []rune{'カ'}, inLasts: []rune{'テ'}, notHeads: []rune{'ジ'}},
+		// kana with long vowel marks are read as written
+		{in: "ユーアー", inHeads: []rune{'ユ'}, notHeads: []rune{'カ'}, notLasts: []rune{'ジ'}},
+		// readings of other parts of speech and names are not candidates
+		{in: "一", inHeads: []rune{'イ'}, inLasts: []rune{'チ'}, notHeads: []rune{'カ', 'ハ', 'マ'}, notLasts: []rune{'ズ', 'メ', 'ト'}},
+		{in: "十分", inHeads: []rune{'ジ'}, notLasts: []rune{'カ'}},
+	}
+
+	for _, tt := range tests {
+		hl, err := analyzeHeadAndLast(tt.in, tt.opts)
+		if err != nil {
+			t.Errorf("analyzeHeadAndLast(%q) got unexpected error: %v", tt.in, err)
+			continue
+		}
+		heads, lasts := hl.candidates(tt.opts)
+		if heads[0] != hl.head || lasts[0] != hl.last {
+			t.Errorf("candidates of %q = %q, %q; first elements must be the best head/last (%q, %q)", tt.in, heads, lasts, hl.head, hl.last)
+		}
+		for _, k := range tt.inHeads {
+			if !slices.Contains(heads, k) {
+				t.Errorf("head candidates of %q = %q; want to contain %q", tt.in, heads, k)
+			}
+		}
+		for _, k := range tt.inLasts {
+			if !slices.Contains(lasts, k) {
+				t.Errorf("last candidates of %q = %q; want to contain %q", tt.in, lasts, k)
+			}
+		}
+		for _, k := range tt.notHeads {
+			if slices.Contains(heads, k) {
+				t.Errorf("head candidates of %q = %q; want not to contain %q", tt.in, heads, k)
+			}
+		}
+		for _, k := range tt.notLasts {
+			if slices.Contains(lasts, k) {
+				t.Errorf("last candidates of %q = %q; want not to contain %q", tt.in, lasts, k)
+			}
+		}
+	}
+}
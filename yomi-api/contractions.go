@@ -0,0 +1,74 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+)
+
+// English contractions (e.g. you've, isn't) and possessives (e.g. Alice's, players').
+// typographic apostrophes (’) are also accepted, since some input methods replace "'" with them.
+var (
+	regexpEnContraction        = regexp.MustCompile(`(?i)\b([a-z]+)(n['’]t|['’](?:s|re|ve|ll|d|m))\b`)
+	regexpEnPluralPossessive   = regexp.MustCompile(`(?i)\b([a-z]+s)['’]\B`)
+	enContractionSuffixReading = map[string]string{
+		"'re": "アー",
+		"'ve": "ブ",
+		"'ll": "ル",
+		"'d":  "ド",
+		"'m":  "ム",
+		"n't": "ント",
+	}
+)
+
+// replaces English contractions and possessives with their readings, which are made of the reading of the base word in the dictionary and the suffix.
+// e.g. they've -> ゼイブ, isn't -> イズント, it's -> イッツ, Alice's -> アリシズ
+//
+// contractions whose base word is not in the dictionary are left as is.
+// irregular ones (e.g. won't, can't) are expected to be replaced by the replace dictionary beforehand.
+func replaceContractions(s string, readingDict map[string]string) string {
+	s = replaceSubmatchFunc(regexpEnContraction, s, func(m []string) (string, bool) {
+		r, ok := readingDict[strings.ToUpper(m[1])]
+		if !ok {
+			return "", false
+		}
+		r = naturalizeEnWordReading(r)
+		suffix := strings.ToLower(strings.ReplaceAll(m[2], "’", "'"))
+		if suffix == "'s" {
+			return withEnSReading(r), true
+		}
+		return r + enContractionSuffixReading[suffix], true
+	})
+	return replaceSubmatchFunc(regexpEnPluralPossessive, s, func(m []string) (string, bool) {
+		if r, ok := readingDict[strings.ToUpper(m[1])]; ok {
+			return naturalizeEnWordReading(r), true
+		}
+		// plural forms may not be in the dictionary (e.g. players')
+		if r, ok := readingDict[strings.ToUpper(m[1][:len(m[1])-1])]; ok {
+			return withEnSReading(naturalizeEnWordReading(r)), true
+		}
+		return "", false
+	})
+}
+
+// appends the reading of "s" (of plurals, possessives and "is") to the reading of the word, according to its last sound.
+// e.g. イット -> イッツ, フレンド -> フレンズ, ブック -> ブックス, エイス -> エイシズ, ドッグ -> ドッグズ
+func withEnSReading(r string) string {
+	if cut, ok := strings.CutSuffix(r, "ト"); ok {
+		return cut + "ツ"
+	}
+	if cut, ok := strings.CutSuffix(r, "ド"); ok {
+		return cut + "ズ"
+	}
+	if cut, ok := strings.CutSuffix(r, "ス"); ok {
+		return cut + "シズ"
+	}
+	if cut, ok := strings.CutSuffix(r, "ズ"); ok {
+		return cut + "ジズ"
+	}
+	for _, e := range []string{"ク", "プ", "フ"} {
+		if strings.HasSuffix(r, e) {
+			return r + "ス"
+		}
+	}
+	return r + "ズ"
+}
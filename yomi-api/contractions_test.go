@@ -0,0 +1,66 @@
+package main
+
+import (
+	"log"
+	"testing"
+)
+
+func TestReplaceContractions(t *testing.T) {
+	dict := map[string]string{
+		"THEY": "ゼイ", "IS": "イズ", "IT": "イットゥ", "ALICE": "アリス", "FRIEND": "フレンドゥ",
+		"BOOK": "ブック", "DOG": "ドッグ", "I": "アイ", "PLAYER": "プレイヤー", "NEWS": "ニューズ",
+	}
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "they've", want: "ゼイブ"},
+		{in: "They're", want: "ゼイアー"},
+		{in: "they'll", want: "ゼイル"},
+		{in: "they'd", want: "ゼイド"},
+		{in: "I'm", want: "アイム"},
+		{in: "isn't", want: "イズント"},
+		{in: "it's", want: "イッツ"},
+		{in: "it’s", want: "イッツ"},
+		{in: "Alice's book", want: "アリシズ book"},
+		{in: "friend's", want: "フレンズ"},
+		{in: "book's", want: "ブックス"},
+		{in: "dog's", want: "ドッグズ"},
+		{in: "players'", want: "プレイヤーズ"},
+		{in: "news' title", want: "ニューズ title"},
+		{in: "zorblax's", want: "zorblax's"},
+		{in: "'s", want: "'s"},
+	}
+
+	for _, tt := range tests {
+		if got := replaceContractions(tt.in, dict); got != tt.want {
+			t.Errorf("replaceContractions(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeText_contractions(t *testing.T) {
+	if err := initialize(); err != nil {
+		log.Fatal(err)
+	}
+
+	tests := []struct {
+		in   string
+		want string
+	}{
+		// regular contractions are read generically
+		{in: "they've", want: "ゼイブ"},
+		{in: "doesn't", want: "ダズント"},
+		{in: "we're", want: "ウィーアー"},
+		{in: "didn't", want: "ディッドント"},
+		// irregular ones are read by the replace dictionary
+		{in: "won't", want: "ウォウント"},
+		{in: "don't", want: "ドント"},
+	}
+
+	for _, tt := range tests {
+		if got := normalizeText(tt.in, analyzeOptions{}); got != tt.want {
+			t.Errorf("normalizeText(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
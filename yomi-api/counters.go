@@ -0,0 +1,227 @@
+package main
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// endings of readings of numbers that become sokuon (促音) before counters.
+// e.g. イチ + ホン -> イッポン
+var (
+	sokuonEndingsHK = []string{"イチ", "ロク", "ハチ", "ジュウ", "ヒャク"} // counters starting with h/k (e.g. 本, 個)
+	sokuonEndingsST = []string{"イチ", "ハチ", "ジュウ"}              // counters starting with s/t (e.g. 冊, 点)
+	sokuonEndingsP  = []string{"イチ", "ハチ", "ジュウ", "ヒャク"}       // counters starting with p (パーセント). 6% is ロクパーセント
+)
+
+// counter word (助数詞) and rules of sound changes with preceding numbers.
+type counter struct {
+	reading string
+	// reading after sokuon (e.g. 本: ポン). if empty, reading is used.
+	afterSokuon string
+	// reading after numbers ending with "ン" except "ヨン" (e.g. 本: ボン). if empty, reading is used.
+	afterN string
+	// reading after "ヨン" (e.g. 分: プン). if empty, reading is used.
+	afterYon string
+	// endings of readings of numbers that become sokuon.
+	sokuonEndings []string
+	// replacements of endings of readings of numbers (e.g. 人: ヨン -> ヨ).
+	numberEndings map[string]string
+	// irregular readings of the whole, keyed by the reading of the number (e.g. 1人: ヒトリ).
+	irregulars map[string]string
+}
+
+var counters = withDurationCounters(map[string]counter{
+	"本": {reading: "ホン", afterSokuon: "ポン", afterN: "ボン", sokuonEndings: sokuonEndingsHK},
+	"匹": {reading: "ヒキ", afterSokuon: "ピキ", afterN: "ビキ", sokuonEndings: sokuonEndingsHK},
+	"杯": {reading: "ハイ", afterSokuon: "パイ", afterN: "バイ", sokuonEndings: sokuonEndingsHK},
+	"分": {reading: "フン", afterSokuon: "プン", afterN: "プン", afterYon: "プン", sokuonEndings: sokuonEndingsHK},
+	"発": {reading: "ハツ", afterSokuon: "パツ", afterN: "パツ", sokuonEndings: sokuonEndingsHK},
+	"泊": {reading: "ハク", afterSokuon: "パク", afterN: "パク", sokuonEndings: sokuonEndingsHK},
+	"品": {reading: "ヒン", afterSokuon: "ピン", afterN: "ピン", sokuonEndings: sokuonEndingsHK},
+	"編": {reading: "ヘン", afterSokuon: "ペン", afterN: "ペン", sokuonEndings: sokuonEndingsHK},
+	"票": {reading: "ヒョウ", afterSokuon: "ピョウ", afterN: "ビョウ", sokuonEndings: sokuonEndingsHK},
+	"個": {reading: "コ", sokuonEndings: sokuonEndingsHK},
+	"回": {reading: "カイ", sokuonEndings: sokuonEndingsHK},
+	"階": {reading: "カイ", afterN: "ガイ", sokuonEndings: sokuonEndingsHK},
+	"軒": {reading: "ケン", afterN: "ゲン", sokuonEndings: sokuonEndingsHK},
+	"件": {reading: "ケン", sokuonEndings: sokuonEndingsHK},
+	"曲": {reading: "キョク", sokuonEndings: sokuonEndingsHK},
+	"冊": {reading: "サツ", sokuonEndings: sokuonEndingsST},
+	"歳": {reading: "サイ", sokuonEndings: sokuonEndingsST, irregulars: map[string]string{"ニジュウ": "ハタチ"}},
+	"才": {reading: "サイ", sokuonEndings: sokuonEndingsST, irregulars: map[string]string{"ニジュウ": "ハタチ"}},
+	"足": {reading: "ソク", afterN: "ゾク", sokuonEndings: sokuonEndingsST},
+	"週": {reading: "シュウ", sokuonEndings: sokuonEndingsST},
+	"通": {reading: "ツウ", sokuonEndings: sokuonEndingsST},
+	"頭": {reading: "トウ", sokuonEndings: sokuonEndingsST},
+	"着": {reading: "チャク", sokuonEndings: sokuonEndingsST},
+	"点": {reading: "テン", sokuonEndings: sokuonEndingsST},
+	"人": {reading: "ニン", numberEndings: yoEnding, irregulars: map[string]string{"イチ": "ヒトリ", "ニ": "フタリ"}},
+	"円": {reading: "エン", numberEndings: yoEnding},
+	"年": {reading: "ネン", numberEndings: yoEnding},
+	"月": {reading: "ガツ", numberEndings: map[string]string{"ヨン": "シ", "ナナ": "シチ", "キュウ": "ク"}},
+	"日": {reading: "ニチ", numberEndings: map[string]string{"ナナ": "シチ", "キュウ": "ク"}, irregulars: map[string]string{
+		"ニ": "フツカ", "サン": "ミッカ", "ヨン": "ヨッカ", "ゴ": "イツカ", "ロク": "ムイカ", "ナナ": "ナノカ", "ハチ": "ヨウカ", "キュウ": "ココノカ",
+		"ジュウ": "トオカ", "ジュウヨン": "ジュウヨッカ", "ニジュウ": "ハツカ", "ニジュウヨン": "ニジュウヨッカ",
+	}},
+	"時":  {reading: "ジ", numberEndings: map[string]string{"ヨン": "ヨ", "ナナ": "シチ", "キュウ": "ク"}, irregulars: map[string]string{"ゼロ": "レイジ"}},
+	"ヶ月": {reading: "カゲツ", sokuonEndings: sokuonEndingsHK},
+	"ヵ月": {reading: "カゲツ", sokuonEndings: sokuonEndingsHK},
+	"か月": {reading: "カゲツ", sokuonEndings: sokuonEndingsHK},
+	"カ月": {reading: "カゲツ", sokuonEndings: sokuonEndingsHK},
+	"箇月": {reading: "カゲツ", sokuonEndings: sokuonEndingsHK},
+	"ヶ所": {reading: "カショ", sokuonEndings: sokuonEndingsHK},
+	"か所": {reading: "カショ", sokuonEndings: sokuonEndingsHK},
+	"カ所": {reading: "カショ", sokuonEndings: sokuonEndingsHK},
+	"箇所": {reading: "カショ", sokuonEndings: sokuonEndingsHK},
+	"%":  {reading: "パーセント", sokuonEndings: sokuonEndingsP},
+	"％":  {reading: "パーセント", sokuonEndings: sokuonEndingsP},
+	"枚":  {reading: "マイ"},
+	"台":  {reading: "ダイ"},
+	"秒":  {reading: "ビョウ"},
+	"度":  {reading: "ド"},
+	"倍":  {reading: "バイ"},
+	"番":  {reading: "バン"},
+})
+
+var yoEnding = map[string]string{"ヨン": "ヨ"}
+
+// adds counters of durations (e.g. 3日間: ミッカカン).
+func withDurationCounters(cs map[string]counter) map[string]counter {
+	for _, w := range []string{"年", "週", "日", "時", "分", "秒"} {
+		cs[w+"間"] = cs[w].withSuffix("カン")
+	}
+	return cs
+}
+
+// number (arabic and/or kanji numerals) followed by a counter word
+var regexpNumberWithCounter = func() *regexp.Regexp {
+	words := make([]string, 0, len(counters))
+	for w := range counters {
+		words = append(words, regexp.QuoteMeta(w))
+	}
+	// longer words first
+	slices.SortFunc(words, func(a, b string) int { return len(b) - len(a) })
+	return regexp.MustCompile(`([0-9,_.〇零一二三四五六七八九十百千万億兆]*[0-9〇零一二三四五六七八九十百千万億兆])(` + strings.Join(words, "|") + `)`)
+}()
+
+// returns the counter whose readings are followed by the suffix.
+func (c counter) withSuffix(suffix string) counter {
+	c.reading += suffix
+	if c.afterSokuon != "" {
+		c.afterSokuon += suffix
+	}
+	if c.afterN != "" {
+		c.afterN += suffix
+	}
+	if c.afterYon != "" {
+		c.afterYon += suffix
+	}
+	irregulars := make(map[string]string, len(c.irregulars))
+	for n, r := range c.irregulars {
+		irregulars[n] = r + suffix
+	}
+	c.irregulars = irregulars
+	return c
+}
+
+// replaces numbers followed by counter words with their readings, applying sound changes.
+// e.g. 3本 -> サンボン, 1匹 -> イッピキ, 10分 -> ジュップン
+//
+// as with replaceJaNumbers, numbers consisting of only kanji are replaced only if they are not a part of words in the dictionary
+// (e.g. 十分 (ジュウブン), 一杯 (イッパイ) are left to the tokenizer).
+func replaceNumbersWithCounter(s string) string {
+	idxs := regexpNumberWithCounter.FindAllStringSubmatchIndex(s, -1)
+	if idxs == nil {
+		return s
+	}
+
+	var b strings.Builder
+	prev := 0
+	for _, idx := range idxs {
+		start, end := idx[2], idx[1]
+		// separators at the beginning are not a part of the number
+		for start < idx[3] && strings.IndexByte(",_.", s[start]) >= 0 {
+			start++
+		}
+		num, cw := s[start:idx[3]], s[idx[4]:idx[5]]
+
+		b.WriteString(s[prev:start])
+		if r, ok := numberWithCounterReading(num, cw); ok && (strings.ContainsAny(num, "0123456789") || !isKanjiNumberInWord(s, start, end)) {
+			b.WriteString(r)
+		} else {
+			b.WriteString(s[start:end])
+		}
+		prev = end
+	}
+	b.WriteString(s[prev:])
+	return b.String()
+}
+
+// checks if the number with the counter word (s[start:end]) written in kanji is a part of a word in the dictionary,
+// either starting from the number (e.g. 十分) or from the preceding character (e.g. 統一人).
+func isKanjiNumberInWord(s string, start, end int) bool {
+	if isInNonNumeralWord(s[start:], end-start) {
+		return true
+	}
+	if r, size := utf8.DecodeLastRuneInString(s[:start]); unicode.Is(unicode.Han, r) {
+		return isInNonNumeralWord(s[start-size:], size+1)
+	}
+	return false
+}
+
+// returns the reading of the number followed by the counter word.
+func numberWithCounterReading(num, counterWord string) (string, bool) {
+	c, ok := counters[counterWord]
+	if !ok {
+		return "", false
+	}
+	return numberWithCounter(num, c)
+}
+
+// returns the reading of the number followed by the counter.
+func numberWithCounter(num string, c counter) (string, bool) {
+	var nr string
+	if strings.ContainsFunc(num, func(r rune) bool { return r > 0x7f }) {
+		var ok bool
+		if nr, ok = jaNumberReading(num); !ok {
+			return "", false
+		}
+	} else {
+		nr = getNumberReading(strings.NewReplacer(",", "", "_", "").Replace(num))
+	}
+	if nr == "" {
+		return "", false
+	}
+
+	if r, ok := c.irregulars[nr]; ok {
+		return r, true
+	}
+	for _, e := range c.sokuonEndings {
+		if cut, found := strings.CutSuffix(nr, e); found {
+			_, size := utf8.DecodeLastRuneInString(e)
+			return cut + e[:len(e)-size] + "ッ" + orDefault(c.afterSokuon, c.reading), true
+		}
+	}
+	for e, repl := range c.numberEndings {
+		if cut, found := strings.CutSuffix(nr, e); found {
+			return cut + repl + c.reading, true
+		}
+	}
+	if strings.HasSuffix(nr, "ヨン") {
+		return nr + orDefault(c.afterYon, c.reading), true
+	}
+	if strings.HasSuffix(nr, "ン") {
+		return nr + orDefault(c.afterN, c.reading), true
+	}
+	return nr + c.reading, true
+}
+
+func orDefault(s, def string) string {
+	if s == "" {
+		return def
+	}
+	return s
+}
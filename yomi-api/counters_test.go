@@ -0,0 +1,116 @@
+package main
+
+import (
+	"log"
+	"testing"
+)
+
+func TestNumberWithCounterReading(t *testing.T) {
+	tests := []struct {
+		num     string
+		counter string
+		want    string
+	}{
+		{num: "3", counter: "本", want: "サンボン"},
+		{num: "1", counter: "本", want: "イッポン"},
+		{num: "4", counter: "本", want: "ヨンホン"},
+		{num: "6", counter: "本", want: "ロッポン"},
+		{num: "13", counter: "本", want: "ジュウサンボン"},
+		{num: "100", counter: "本", want: "ヒャッポン"},
+		{num: "1000", counter: "本", want: "センボン"},
+		{num: "1", counter: "匹", want: "イッピキ"},
+		{num: "3", counter: "匹", want: "サンビキ"},
+		{num: "6", counter: "杯", want: "ロッパイ"},
+		{num: "10", counter: "分", want: "ジュップン"},
+		{num: "3", counter: "分", want: "サンプン"},
+		{num: "4", counter: "分", want: "ヨンプン"},
+		{num: "5", counter: "分", want: "ゴフン"},
+		{num: "8", counter: "個", want: "ハッコ"},
+		{num: "3", counter: "階", want: "サンガイ"},
+		{num: "6", counter: "冊", want: "ロクサツ"},
+		{num: "8", counter: "冊", want: "ハッサツ"},
+		{num: "3", counter: "足", want: "サンゾク"},
+		{num: "1", counter: "人", want: "ヒトリ"},
+		{num: "2", counter: "人", want: "フタリ"},
+		{num: "4", counter: "人", want: "ヨニン"},
+		{num: "11", counter: "人", want: "ジュウイチニン"},
+		{num: "20", counter: "歳", want: "ハタチ"},
+		{num: "4", counter: "円", want: "ヨエン"},
+		{num: "1,000", counter: "円", want: "センエン"},
+		{num: "1.5", counter: "本", want: "イッテンゴホン"},
+		{num: "4", counter: "月", want: "シガツ"},
+		{num: "1", counter: "日", want: "イチニチ"},
+		{num: "3", counter: "日", want: "ミッカ"},
+		{num: "17", counter: "日", want: "ジュウシチニチ"},
+		{num: "9", counter: "時", want: "クジ"},
+		{num: "4", counter: "時間", want: "ヨジカン"},
+		{num: "2", counter: "日間", want: "フツカカン"},
+		{num: "三", counter: "本", want: "サンボン"},
+		{num: "二十一", counter: "匹", want: "ニジュウイッピキ"},
+		{num: "3万", counter: "人", want: "サンマンニン"},
+	}
+
+	for _, tt := range tests {
+		got, ok := numberWithCounterReading(tt.num, tt.counter)
+		if !ok || got != tt.want {
+			t.Errorf("numberWithCounterReading(%s, %s) = %s, %v, want %s", tt.num, tt.counter, got, ok, tt.want)
+		}
+	}
+}
+
+func TestReplaceNumbersWithCounter(t *testing.T) {
+	if err := initialize(); err != nil {
+		log.Fatal(err)
+	}
+
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "3本", want: "サンボン"},
+		{in: "猫が1匹いる", want: "猫がイッピキいる"},
+		{in: "あと10分で着く", want: "あとジュップンで着く"},
+		{in: "三本目", want: "サンボン目"},
+		{in: "2人で", want: "フタリで"},
+		{in: "3000円と500円", want: "サンゼンエンとゴヒャクエン"},
+		// words containing kanji numerals are left to the tokenizer
+		{in: "十分", want: "十分"},
+		{in: "統一人", want: "統一人"},
+	}
+
+	for _, tt := range tests {
+		if got := replaceNumbersWithCounter(tt.in); got != tt.want {
+			t.Errorf("replaceNumbersWithCounter(%s) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestEffectiveHeadAndLast_counters(t *testing.T) {
+	if err := initialize(); err != nil {
+		log.Fatal(err)
+	}
+
+	tests := []struct {
+		in   string
+		head rune
+		last rune
+	}{
+		{in: "3本", head: 'サ', last: 'ン'},
+		{in: "1匹", head: 'イ', last: 'キ'},
+		{in: "6杯", head: 'ロ', last: 'イ'},
+		{in: "10分", head: 'ジ', last: 'ン'},
+		{in: "8個", head: 'ハ', last: 'コ'},
+		{in: "２人", head: 'フ', last: 'リ'},
+	}
+
+	for _, tt := range tests {
+		hl, err := analyzeHeadAndLast(tt.in, analyzeOptions{normalization: normalizationFull})
+		if err != nil {
+			t.Errorf("analyzeHeadAndLast(%s) returned error: %v", tt.in, err)
+			continue
+		}
+		if hl.head != tt.head || hl.last != tt.last {
+			t.Errorf("analyzeHeadAndLast(%s) = %c, %c, want %c, %c", tt.in, hl.head, hl.last, tt.head, tt.last)
+		}
+	}
+}
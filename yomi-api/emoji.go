@@ -0,0 +1,145 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/rivo/uniseg"
+	"golang.org/x/text/language"
+	"golang.org/x/text/language/display"
+)
+
+// how to treat emoji.
+type emojiPolicy string
+
+const (
+	// emoji are read by their short names (e.g. 🐱 -> ネコ). this is the default of /v1/ endpoints.
+	emojiRead emojiPolicy = "read"
+	// emoji are removed.
+	emojiIgnore emojiPolicy = "ignore"
+)
+
+// short names of emoji (emoji without variation selectors -> name).
+var emojiNames = make(map[string]string)
+
+// format of emoji annotations in JSON, which is the same layout as annotations of CLDR JSON (cldr-json).
+type emojiAnnotations struct {
+	Annotations struct {
+		Annotations map[string]struct {
+			Default []string `json:"default"`
+			TTS     []string `json:"tts"`
+		} `json:"annotations"`
+	} `json:"annotations"`
+}
+
+// loads short names of emoji from the annotations file.
+// the embedded one is a hand-written list of short names of common emoji (and some ZWJ sequences), chosen to be read easily in shiritori.
+// emoji with skin tones are read by the names of base emoji, since skin tone modifiers are stripped on lookup.
+func parseEmojiAnnotations(path string) error {
+	b, err := dicts.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to open emoji annotations file: %w", err)
+	}
+	var ann emojiAnnotations
+	if err := json.Unmarshal(b, &ann); err != nil {
+		return fmt.Errorf("failed to parse emoji annotations file: %w", err)
+	}
+	for e, a := range ann.Annotations.Annotations {
+		if len(a.TTS) == 0 {
+			continue
+		}
+		emojiNames[stripEmojiModifiers(e)] = a.TTS[0]
+	}
+	return nil
+}
+
+// replaces emoji with their short names, or removes them if the policy is emojiIgnore.
+// emoji are processed per grapheme cluster, so ZWJ sequences, flags, keycaps and emoji with skin tones are treated as a whole.
+// emoji whose names are unknown are left as is.
+func replaceEmoji(s string, policy emojiPolicy) string {
+	if policy == "" {
+		return s
+	}
+
+	var b strings.Builder
+	b.Grow(len(s))
+	state := -1
+	for rest := s; rest != ""; {
+		var c string
+		c, rest, _, state = uniseg.FirstGraphemeClusterInString(rest, state)
+		if !isEmojiCluster(c) {
+			b.WriteString(c)
+			continue
+		}
+		if policy == emojiIgnore {
+			b.WriteString(" ")
+			continue
+		}
+		if name, ok := emojiName(c); ok {
+			b.WriteString(" " + name + " ")
+		} else {
+			b.WriteString(c)
+		}
+	}
+	return b.String()
+}
+
+// returns the name of the emoji (grapheme cluster).
+func emojiName(c string) (string, bool) {
+	// keycaps (e.g. 1️⃣) are read as their base characters
+	if base, ok := strings.CutSuffix(strings.ReplaceAll(c, "\uFE0F", ""), "\u20E3"); ok {
+		return base, true
+	}
+	if rs := []rune(c); len(rs) == 2 && isRegionalIndicator(rs[0]) && isRegionalIndicator(rs[1]) {
+		return flagName(rs[0], rs[1])
+	}
+
+	// ZWJ sequences not in annotations are unknown, since their first components often mean different things (e.g. 🏳️‍🌈 is not a white flag)
+	name, ok := emojiNames[stripEmojiModifiers(c)]
+	return name, ok
+}
+
+// returns the Japanese name of the region represented by the flag (e.g. 🇯🇵 -> 日本).
+func flagName(r1, r2 rune) (string, bool) {
+	region, err := language.ParseRegion(string([]rune{r1 - 0x1F1E6 + 'A', r2 - 0x1F1E6 + 'A'}))
+	if err != nil {
+		return "", false
+	}
+	name := display.Japanese.Regions().Name(region)
+	return name, name != ""
+}
+
+// removes variation selectors, skin tone modifiers and tags from the emoji, which don't change its name essentially.
+func stripEmojiModifiers(e string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '\uFE0E' || r == '\uFE0F' || isSkinToneModifier(r) || 0xE0020 <= r && r <= 0xE007F {
+			return -1
+		}
+		return r
+	}, e)
+}
+
+// checks if the grapheme cluster is an emoji.
+func isEmojiCluster(c string) bool {
+	if strings.ContainsAny(c, "\uFE0F\u200D\u20E3") {
+		return true
+	}
+	if _, ok := emojiNames[stripEmojiModifiers(c)]; ok {
+		return true
+	}
+	for _, r := range c {
+		// Enclosed Alphanumeric Supplement to Symbols and Pictographs Extended-A
+		// (including regional indicators, pictographs, emoticons and transport symbols)
+		return 0x1F100 <= r && r <= 0x1FAFF
+	}
+	return false
+}
+
+func isRegionalIndicator(r rune) bool {
+	return 0x1F1E6 <= r && r <= 0x1F1FF
+}
+
+func isSkinToneModifier(r rune) bool {
+	return 0x1F3FB <= r && r <= 0x1F3FF
+}
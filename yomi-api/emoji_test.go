@@ -0,0 +1,74 @@
+package main
+
+import (
+	"log"
+	"testing"
+)
+
+func TestReplaceEmoji(t *testing.T) {
+	if err := initialize(); err != nil {
+		log.Fatal(err)
+	}
+
+	tests := []struct {
+		in     string
+		policy emojiPolicy
+		want   string
+	}{
+		{in: "🐱", policy: emojiRead, want: " ネコ "},
+		{in: "今日も🍕", policy: emojiRead, want: "今日も ピザ "},
+		{in: "🇯🇵", policy: emojiRead, want: " 日本 "},
+		// skin tones and variation selectors don't change names
+		{in: "👍🏽", policy: emojiRead, want: " いいね "},
+		{in: "❤️", policy: emojiRead, want: " ハート "},
+		// ZWJ sequences are read as a whole
+		{in: "🐻‍❄️", policy: emojiRead, want: " シロクマ "},
+		// skin tones don't change names of ZWJ sequences either
+		{in: "🧑🏽‍💻", policy: emojiRead, want: " プログラマー "},
+		// ZWJ sequences not in annotations are unknown, rather than read by the first component
+		{in: "🏳️‍🌈", policy: emojiRead, want: "🏳️‍🌈"},
+		{in: "1️⃣", policy: emojiRead, want: " 1 "},
+		// non-emoji characters are left as is
+		{in: "猫☆", policy: emojiRead, want: "猫☆"},
+		{in: "🐱ネコ👍🏽", policy: emojiIgnore, want: " ネコ "},
+		{in: "🐱", policy: "", want: "🐱"},
+	}
+
+	for _, tt := range tests {
+		if got := replaceEmoji(tt.in, tt.policy); got != tt.want {
+			t.Errorf("replaceEmoji(%q, %q) = %q, want %q", tt.in, tt.policy, got, tt.want)
+		}
+	}
+}
+
+func TestAnalyzeHeadAndLast_emoji(t *testing.T) {
+	if err := initialize(); err != nil {
+		log.Fatal(err)
+	}
+
+	tests := []struct {
+		in   string
+		opts analyzeOptions
+		head rune
+		last rune
+	}{
+		{in: "🐱", opts: analyzeOptions{emoji: emojiRead}, head: 'ネ', last: 'コ'},
+		{in: "おはよう🐶", opts: analyzeOptions{emoji: emojiRead}, head: 'オ', last: 'ヌ'},
+		{in: "おはよう🐶", opts: analyzeOptions{emoji: emojiIgnore}, head: 'オ', last: 'ウ'},
+	}
+
+	for _, tt := range tests {
+		hl, err := analyzeHeadAndLast(tt.in, tt.opts)
+		if err != nil {
+			t.Errorf("analyzeHeadAndLast(%q) returned error: %v", tt.in, err)
+			continue
+		}
+		if hl.head != tt.head || hl.last != tt.last {
+			t.Errorf("analyzeHeadAndLast(%q) = %c, %c, want %c, %c", tt.in, hl.head, hl.last, tt.head, tt.last)
+		}
+	}
+
+	if _, err := analyzeHeadAndLast("🐱🐶", analyzeOptions{emoji: emojiIgnore}); unreadableReasonOf(err) != reasonOnlySymbols {
+		t.Errorf("analyzeHeadAndLast(🐱🐶) with emoji ignored got %v; want error with reason %q", err, reasonOnlySymbols)
+	}
+}
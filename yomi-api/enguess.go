@@ -0,0 +1,370 @@
+package main
+
+import (
+	"strings"
+)
+
+// rule-based estimation of the pronunciation of English words that are not in the dictionary.
+//
+// the word is first split into phones by spelling rules (e.g. silent e, vowel digraphs, r-colored vowels),
+// then the phones are converted to katakana in the manner of loanwords.
+// a phone is either:
+//   - a consonant (e.g. "k", "sh")
+//   - a vowel: one of "aiueo" followed by katakana for the rest of the vowel (e.g. "aイ" for "ai", "oー" for "o:")
+//   - "Q" (sokuon) or "N" (moraic nasal)
+
+// kana of each consonant followed by vowels a, i, u, e, o.
+var enKanaRows = map[string][5]string{
+	"":   {"ア", "イ", "ウ", "エ", "オ"},
+	"k":  {"カ", "キ", "ク", "ケ", "コ"},
+	"g":  {"ガ", "ギ", "グ", "ゲ", "ゴ"},
+	"s":  {"サ", "シ", "ス", "セ", "ソ"},
+	"z":  {"ザ", "ジ", "ズ", "ゼ", "ゾ"},
+	"t":  {"タ", "ティ", "トゥ", "テ", "ト"},
+	"d":  {"ダ", "ディ", "ドゥ", "デ", "ド"},
+	"n":  {"ナ", "ニ", "ヌ", "ネ", "ノ"},
+	"h":  {"ハ", "ヒ", "フ", "ヘ", "ホ"},
+	"f":  {"ファ", "フィ", "フ", "フェ", "フォ"},
+	"b":  {"バ", "ビ", "ブ", "ベ", "ボ"},
+	"v":  {"バ", "ビ", "ブ", "ベ", "ボ"},
+	"p":  {"パ", "ピ", "プ", "ペ", "ポ"},
+	"m":  {"マ", "ミ", "ム", "メ", "モ"},
+	"y":  {"ヤ", "イ", "ユ", "イェ", "ヨ"},
+	"r":  {"ラ", "リ", "ル", "レ", "ロ"},
+	"l":  {"ラ", "リ", "ル", "レ", "ロ"},
+	"w":  {"ワ", "ウィ", "ウ", "ウェ", "ウォ"},
+	"sh": {"シャ", "シ", "シュ", "シェ", "ショ"},
+	"ch": {"チャ", "チ", "チュ", "チェ", "チョ"},
+	"j":  {"ジャ", "ジ", "ジュ", "ジェ", "ジョ"},
+	"th": {"サ", "シ", "ス", "セ", "ソ"},
+	"ts": {"ツァ", "ツィ", "ツ", "ツェ", "ツォ"},
+}
+
+// kana of consonants not followed by vowels, other than ones in the "u" column (e.g. t -> ト).
+var enEpentheticKana = map[string]string{
+	"t": "ト", "d": "ド", "ch": "チ", "j": "ジ", "sh": "シュ", "y": "イ",
+}
+
+// consonants that are palatalized before "y" (e.g. cute -> キュート), and small kana for the palatalized vowels.
+const enPalatalizableConsonants = "kgnhbpmrlv"
+
+var enSmallYaKana = map[byte]string{'a': "ャ", 'u': "ュ", 'o': "ョ"}
+
+// returns the estimated reading of the English word in katakana.
+// returns false if the word is not likely to be pronounced as a word, that is, it has no vowels or it is in upper case (likely an acronym).
+func guessEnWordReading(word string) (string, bool) {
+	if len(word) >= 2 && word == strings.ToUpper(word) {
+		return "", false
+	}
+	w := strings.ToLower(word)
+	if !strings.ContainsAny(w, "aiueoy") {
+		return "", false
+	}
+	return enPhonesToKana(enPhonesOf(w)), true
+}
+
+// splits the English word (in lower case) into phones.
+func enPhonesOf(w string) []string {
+	switch {
+	case strings.HasPrefix(w, "kn"), strings.HasPrefix(w, "wr"):
+		w = w[1:]
+	case strings.HasPrefix(w, "x"):
+		w = "z" + w[1:]
+	}
+	if strings.HasSuffix(w, "mb") {
+		w = w[:len(w)-1]
+	}
+
+	// final "e" after a consonant is silent, unless it is the only vowel (e.g. be, she)
+	end := len(w)
+	silentE := len(w) >= 3 && w[len(w)-1] == 'e' && !isEnVowelLetter(w[len(w)-2]) && strings.ContainsAny(w[:len(w)-2], "aiueoy")
+	if silentE {
+		end--
+	}
+	monosyllable := countEnVowelGroups(w[:end]) == 1
+
+	var ps []string
+	// whether the last phone is a short vowel spelled with a single letter
+	lastShort := false
+	for i := 0; i < end; {
+		if isEnVowelAt(w, i) {
+			vs, n, short := enVowelPhones(w, i, end, silentE, monosyllable)
+			ps = append(ps, vs...)
+			lastShort = short
+			i += n
+			continue
+		}
+
+		c, n := w[i], 1
+		var cs []string
+		switch next := byteAt(w, i+1); {
+		case strings.HasPrefix(w[i:], "tch"):
+			cs, n = []string{"Q", "ch"}, 3
+		case c == 'c' && next == 'h', c == 's' && next == 'h', c == 't' && next == 'h':
+			cs, n = []string{w[i : i+2]}, 2
+		case c == 'p' && next == 'h':
+			cs, n = []string{"f"}, 2
+		case c == 'w' && next == 'h':
+			cs, n = []string{"w"}, 2
+		case c == 'c' && next == 'k':
+			cs, n = []string{"Q", "k"}, 2
+		case c == 'q' && next == 'u':
+			cs, n = []string{"k", "w"}, 2
+		case c == 'g' && next == 'h':
+			// silent except at the beginning (e.g. night, ghost)
+			if i == 0 {
+				cs = []string{"g"}
+			}
+			n = 2
+		case c == 'd' && next == 'g' && silentE && i+2 == end:
+			cs = []string{"Q"}
+		case c == 'x':
+			cs = []string{"k", "s"}
+		case c == 'n' && next == 'n' && i+2 == end:
+			cs, n = []string{"N"}, 2
+		case i > 0 && (c == 'n' && (i+1 == end || !isEnVowelAt(w, i+1)) || c == 'm' && (next == 'b' || next == 'p')):
+			cs = []string{"N"}
+		case c == next && lastShort && (strings.IndexByte("ptkgdb", c) >= 0 || c == 's' && i+2 < end):
+			cs, n = []string{"Q", enConsonantAt(w, i+1, end, silentE)}, 2
+		case c == next:
+			cs, n = []string{enConsonantAt(w, i+1, end, silentE)}, 2
+		case i+1 == end && !silentE && lastShort && strings.IndexByte("ptkgd", c) >= 0:
+			// final consonant after a short vowel is geminated (e.g. cat -> キャット)
+			cs = []string{"Q", string(c)}
+		default:
+			cs = []string{enConsonantAt(w, i, end, silentE)}
+		}
+		ps = append(ps, cs...)
+		lastShort = false
+		i += n
+	}
+	return ps
+}
+
+// returns the phone of the single consonant letter at w[i].
+func enConsonantAt(w string, i, end int, silentE bool) string {
+	switch next := byteAt(w, i+1); w[i] {
+	case 'c':
+		if next == 'e' || next == 'i' || next == 'y' {
+			return "s"
+		}
+		return "k"
+	case 'g':
+		if silentE && i+1 == end {
+			return "j"
+		}
+		return "g"
+	default:
+		return string(w[i])
+	}
+}
+
+// returns phones of the vowel starting at w[i], the number of letters consumed, and whether it is a short vowel spelled with a single letter.
+func enVowelPhones(w string, i, end int, silentE, monosyllable bool) ([]string, int, bool) {
+	rest := w[i:end]
+	// the letter following the vowel (of n letters) is not a vowel
+	closedAfter := func(n int) bool { return i+n >= end || !isEnVowelLetter(w[i+n]) }
+	// "u" is pronounced with "y" except after some consonants (e.g. cute -> キュート, rule -> ルール)
+	withY := func(v string) []string {
+		if i > 0 && strings.IndexByte("rljstd", w[i-1]) >= 0 {
+			return []string{v}
+		}
+		return []string{"y", v}
+	}
+
+	switch {
+	case strings.HasPrefix(rest, "igh"):
+		return []string{"aイ"}, 3, false
+	case strings.HasPrefix(rest, "eau"):
+		return []string{"oー"}, 3, false
+	}
+	// r-colored digraphs
+	if len(rest) >= 3 && rest[2] == 'r' && closedAfter(3) {
+		switch rest[:2] {
+		case "ee", "ea":
+			return []string{"iア"}, 3, false
+		case "ai":
+			return []string{"eア"}, 3, false
+		case "oo", "ou", "oa":
+			return []string{"oア"}, 3, false
+		}
+	}
+	if len(rest) >= 2 {
+		atEnd := i+2 == len(w)
+		switch rest[:2] {
+		case "ee", "ea":
+			return []string{"iー"}, 2, false
+		case "oo":
+			return []string{"uー"}, 2, false
+		case "ou":
+			if i > 0 && w[i-1] == 'y' {
+				return []string{"uー"}, 2, false
+			}
+			return []string{"aウ"}, 2, false
+		case "ow":
+			if atEnd {
+				return []string{"oー"}, 2, false
+			}
+			return []string{"aウ"}, 2, false
+		case "ai", "ay", "ei":
+			return []string{"eイ"}, 2, false
+		case "oi", "oy":
+			return []string{"oイ"}, 2, false
+		case "au", "aw", "oa":
+			return []string{"oー"}, 2, false
+		case "ew":
+			return withY("uー"), 2, false
+		case "ue":
+			if atEnd {
+				return withY("uー"), 2, false
+			}
+		case "ie":
+			if atEnd {
+				return []string{"aイ"}, 2, false
+			}
+			return []string{"iー"}, 2, false
+		case "ey":
+			if atEnd {
+				return []string{"iー"}, 2, false
+			}
+		}
+	}
+
+	v := w[i]
+	// long vowels before the silent e (e.g. cake, time, table)
+	if silentE && (i == 0 || !isEnVowelLetter(w[i-1])) {
+		single := i+2 == end && !isEnVowelLetter(w[i+1])
+		withL := i+3 == end && w[i+2] == 'l' && !isEnVowelLetter(w[i+1]) && w[i+1] != 'l'
+		if single && w[i+1] == 'r' {
+			switch v {
+			case 'a':
+				return []string{"eア"}, 2, false
+			case 'e':
+				return []string{"iア"}, 2, false
+			case 'i', 'y':
+				return []string{"aイア"}, 2, false
+			case 'o':
+				return []string{"oア"}, 2, false
+			case 'u':
+				return withY("uア"), 2, false
+			}
+		}
+		if single || withL {
+			switch v {
+			case 'a':
+				return []string{"eー"}, 1, false
+			case 'e':
+				return []string{"iー"}, 1, false
+			case 'i', 'y':
+				return []string{"aイ"}, 1, false
+			case 'o':
+				return []string{"oー"}, 1, false
+			case 'u':
+				return withY("uー"), 1, false
+			}
+		}
+	}
+
+	// r-colored vowels (e.g. car, her, for)
+	if byteAt(w, i+1) == 'r' && i+1 < end && closedAfter(2) && byteAt(w, i+2) != 'r' {
+		if v == 'o' {
+			return []string{"oー"}, 2, false
+		}
+		return []string{"aー"}, 2, false
+	}
+
+	switch v {
+	case 'y':
+		if i+1 < len(w) {
+			return []string{"i"}, 1, true
+		}
+		if monosyllable {
+			return []string{"aイ"}, 1, false
+		}
+		return []string{"iー"}, 1, false
+	case 'e':
+		if i+1 == len(w) && monosyllable {
+			return []string{"iー"}, 1, false
+		}
+	case 'o':
+		if i+1 == len(w) && monosyllable {
+			return []string{"oー"}, 1, false
+		}
+	case 'u':
+		// "u" in closed syllables is read as "a" (e.g. cut, under), otherwise as "u:" (e.g. menu, super)
+		if i+1 < end && !isEnVowelAt(w, i+1) && (i+2 >= end || !isEnVowelAt(w, i+2)) {
+			return []string{"a"}, 1, true
+		}
+		return withY("uー"), 1, false
+	}
+	return []string{string(v)}, 1, true
+}
+
+// converts phones to katakana.
+func enPhonesToKana(ps []string) string {
+	var b strings.Builder
+	for i := 0; i < len(ps); i++ {
+		p := ps[i]
+		switch {
+		case p == "Q":
+			b.WriteString("ッ")
+		case p == "N":
+			b.WriteString("ン")
+		case isEnVowelPhone(p):
+			b.WriteString(enKanaRows[""][strings.IndexByte("aiueo", p[0])] + p[1:])
+		case i+2 < len(ps) && ps[i+1] == "y" && isEnVowelPhone(ps[i+2]) && strings.Contains(enPalatalizableConsonants, p) && enSmallYaKana[ps[i+2][0]] != "":
+			// palatalized consonant (e.g. cute -> キュート)
+			v := ps[i+2]
+			b.WriteString(enKanaRows[p][1] + enSmallYaKana[v[0]] + v[1:])
+			i += 2
+		case i+1 < len(ps) && isEnVowelPhone(ps[i+1]):
+			v := ps[i+1]
+			b.WriteString(enKanaRows[p][strings.IndexByte("aiueo", v[0])] + v[1:])
+			i++
+		default:
+			if k, ok := enEpentheticKana[p]; ok {
+				b.WriteString(k)
+			} else {
+				b.WriteString(enKanaRows[p][2])
+			}
+		}
+	}
+	return b.String()
+}
+
+func isEnVowelPhone(p string) bool {
+	return p != "" && strings.IndexByte("aiueo", p[0]) >= 0
+}
+
+func isEnVowelLetter(c byte) bool {
+	return strings.IndexByte("aiueo", c) >= 0
+}
+
+// checks if w[i] is pronounced as a vowel. "y" is a vowel unless it is at the beginning or followed by a vowel.
+func isEnVowelAt(w string, i int) bool {
+	if i >= len(w) {
+		return false
+	}
+	if w[i] == 'y' {
+		return i > 0 && !isEnVowelLetter(byteAt(w, i+1))
+	}
+	return isEnVowelLetter(w[i])
+}
+
+func countEnVowelGroups(w string) int {
+	n := 0
+	for i := range len(w) {
+		if isEnVowelAt(w, i) && (i == 0 || !isEnVowelAt(w, i-1)) {
+			n++
+		}
+	}
+	return n
+}
+
+// returns w[i], or 0 if i is out of range.
+func byteAt(w string, i int) byte {
+	if i < len(w) {
+		return w[i]
+	}
+	return 0
+}
@@ -0,0 +1,45 @@
+package main
+
+import "testing"
+
+func TestGuessEnWordReading(t *testing.T) {
+	tests := []struct {
+		in     string
+		want   string
+		wantOk bool
+	}{
+		{in: "crypto", want: "クリプト", wantOk: true},
+		{in: "Crypto", want: "クリプト", wantOk: true},
+		{in: "cake", want: "ケーク", wantOk: true},
+		{in: "time", want: "タイム", wantOk: true},
+		{in: "table", want: "テーブル", wantOk: true},
+		{in: "apple", want: "アップル", wantOk: true},
+		{in: "back", want: "バック", wantOk: true},
+		{in: "match", want: "マッチ", wantOk: true},
+		{in: "cute", want: "キュート", wantOk: true},
+		{in: "rule", want: "ルール", wantOk: true},
+		{in: "fire", want: "ファイア", wantOk: true},
+		{in: "night", want: "ナイト", wantOk: true},
+		{in: "house", want: "ハウス", wantOk: true},
+		{in: "show", want: "ショー", wantOk: true},
+		{in: "happy", want: "ハッピー", wantOk: true},
+		{in: "fly", want: "フライ", wantOk: true},
+		{in: "system", want: "システム", wantOk: true},
+		{in: "party", want: "パーティー", wantOk: true},
+		{in: "number", want: "ナンバー", wantOk: true},
+		{in: "phone", want: "フォーン", wantOk: true},
+		{in: "bridge", want: "ブリッジ", wantOk: true},
+		{in: "lightning", want: "ライトニング", wantOk: true},
+		{in: "npub", want: "ヌパブ", wantOk: true},
+		{in: "knight", want: "ナイト", wantOk: true},
+		{in: "nstr", want: "", wantOk: false},
+		{in: "NASA", want: "", wantOk: false},
+	}
+
+	for _, tt := range tests {
+		got, ok := guessEnWordReading(tt.in)
+		if got != tt.want || ok != tt.wantOk {
+			t.Errorf("guessEnWordReading(%q) = %q, %v; want %q, %v", tt.in, got, ok, tt.want, tt.wantOk)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+)
+
+// alphanumeric words that may consist of multiple parts (e.g. GitHub, iPhone15, snake_case, IPv6)
+var regexpEnCompoundWord = regexp.MustCompile(`[A-Za-z0-9_]*[A-Za-z][A-Za-z0-9_]*`)
+
+// splits compound alphanumeric words into parts separated by spaces, so that each part is read by the English and number readers.
+// words are split at underscores, boundaries between letters and digits, and boundaries of CamelCase.
+// e.g. GitHub -> Git Hub, iPhone15 -> i Phone 15, snake_case -> snake case, IPv6 -> IP v 6
+//
+// words in the English dictionary (including brand names in custom.dic, e.g. GitHub) are kept whole at each step,
+// e.g. iPhone15 -> iPhone 15 (if IPHONE is in the dictionary).
+func splitEnCompoundWords(s string, readingDict map[string]string) string {
+	return regexpEnCompoundWord.ReplaceAllStringFunc(s, func(w string) string {
+		if _, ok := readingDict[strings.ToUpper(w)]; ok {
+			return w
+		}
+
+		var parts []string
+		for _, chunk := range strings.FieldsFunc(w, func(r rune) bool { return r == '_' }) {
+			if _, ok := readingDict[strings.ToUpper(chunk)]; ok {
+				parts = append(parts, chunk)
+				continue
+			}
+			for _, p := range splitAlphanumeric(chunk) {
+				if _, ok := readingDict[strings.ToUpper(p)]; ok || !isASCIILetter(p[0]) {
+					parts = append(parts, p)
+					continue
+				}
+				parts = append(parts, splitCamelCase(p)...)
+			}
+		}
+		return strings.Join(parts, " ")
+	})
+}
+
+// splits the word at boundaries between letters and digits.
+func splitAlphanumeric(w string) []string {
+	var parts []string
+	start := 0
+	for i := 1; i < len(w); i++ {
+		if isASCIILetter(w[i-1]) != isASCIILetter(w[i]) {
+			parts = append(parts, w[start:i])
+			start = i
+		}
+	}
+	return append(parts, w[start:])
+}
+
+// splits the word consisting of letters at boundaries of CamelCase.
+// e.g. GitHub -> Git Hub, XMLHttp -> XML Http, IPv -> IP v
+func splitCamelCase(w string) []string {
+	var parts []string
+	start := 0
+	for i := 1; i < len(w); i++ {
+		switch {
+		case isASCIILower(w[i-1]) && isASCIIUpper(w[i]):
+			// e.g. Git|Hub
+		case isASCIIUpper(w[i-1]) && isASCIIUpper(w[i]) && lowerRunLen(w, i+1) >= 2:
+			// an acronym followed by a capitalized word, e.g. XML|Http
+		case isASCIIUpper(w[i-1]) && i-start >= 2 && lowerRunLen(w, i) == 1:
+			// an acronym followed by a single lowercase letter, e.g. IP|v
+		default:
+			continue
+		}
+		parts = append(parts, w[start:i])
+		start = i
+	}
+	return append(parts, w[start:])
+}
+
+// returns the length of the run of lowercase letters starting at w[i].
+func lowerRunLen(w string, i int) int {
+	n := 0
+	for ; i+n < len(w) && isASCIILower(w[i+n]); n++ {
+	}
+	return n
+}
+
+func isASCIILetter(c byte) bool {
+	return isASCIIUpper(c) || isASCIILower(c)
+}
+
+func isASCIIUpper(c byte) bool {
+	return 'A' <= c && c <= 'Z'
+}
+
+func isASCIILower(c byte) bool {
+	return 'a' <= c && c <= 'z'
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"log"
+	"testing"
+)
+
+func TestSplitEnCompoundWords(t *testing.T) {
+	dict := map[string]string{"IPHONE": "アイフォーン", "GITHUB": "ギットハブ"}
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "hello", want: "hello"},
+		{in: "NHK", want: "NHK"},
+		{in: "Hello World", want: "Hello World"},
+		{in: "GitHub", want: "GitHub"},
+		{in: "GitLab", want: "Git Lab"},
+		{in: "iPhone15", want: "iPhone 15"},
+		{in: "iPad", want: "i Pad"},
+		{in: "snake_case", want: "snake case"},
+		{in: "IPv6", want: "IP v 6"},
+		{in: "XMLHttpRequest", want: "XML Http Request"},
+		{in: "4K", want: "4 K"},
+		{in: "github_actions", want: "github actions"},
+		{in: "1_000", want: "1_000"},
+		{in: "日本語とCamelCase", want: "日本語とCamel Case"},
+	}
+
+	for _, tt := range tests {
+		if got := splitEnCompoundWords(tt.in, dict); got != tt.want {
+			t.Errorf("splitEnCompoundWords(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestEffectiveHeadAndLast_compoundWords(t *testing.T) {
+	if err := initialize(); err != nil {
+		log.Fatal(err)
+	}
+
+	tests := []struct {
+		in   string
+		head rune
+		last rune
+	}{
+		{in: "NHK", head: 'エ', last: 'イ'},
+		{in: "GitHub", head: 'ギ', last: 'ブ'},
+		{in: "iPhone15", head: 'ア', last: 'ゴ'},
+		{in: "snake_case", head: 'ス', last: 'ス'},
+		{in: "IPv6", head: 'ア', last: 'ク'},
+		{in: "NostrClient", head: 'ノ', last: 'ト'},
+	}
+
+	for _, tt := range tests {
+		hl, err := analyzeHeadAndLast(tt.in, analyzeOptions{normalization: normalizationFull})
+		if err != nil {
+			t.Errorf("analyzeHeadAndLast(%s) returned error: %v", tt.in, err)
+			continue
+		}
+		if hl.head != tt.head || hl.last != tt.last {
+			t.Errorf("analyzeHeadAndLast(%s) = %c, %c, want %c, %c", tt.in, hl.head, hl.last, tt.head, tt.last)
+		}
+	}
+}
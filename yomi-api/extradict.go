@@ -0,0 +1,265 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// kind of extra dictionary.
+type dictKind string
+
+const (
+	// English word readings, same as dicts/custom.dic
+	dictKindReading dictKind = "reading"
+	// words replaced with their readings before tokenization, same as dicts/replace.dic
+	dictKindReplace dictKind = "replace"
+	// symbols and units, same as dicts/symbol.dic
+	dictKindSymbol dictKind = "symbol"
+)
+
+// file names of extra dictionaries in the extra dictionary directory.
+var extraDictFileNames = map[dictKind]string{
+	dictKindReading: "custom.dic",
+	dictKindReplace: "replace.dic",
+	dictKindSymbol:  "symbol.dic",
+}
+
+var (
+	// guards readingDict, replaceDict and symbolDict.
+	// after initialization, they are never modified in place, but replaced as a whole when extra dictionaries are updated.
+	dictsMu sync.RWMutex
+
+	// serializes updates of extra dictionaries.
+	extraDictsMu sync.Mutex
+	// directory where extra dictionaries are loaded from and persisted to. empty if extra dictionaries are disabled.
+	extraDictDir string
+	// entries of extra dictionaries (word in upper case -> reading in katakana).
+	extraDictEntries = map[dictKind]map[string]string{
+		dictKindReading: {},
+		dictKindReplace: {},
+		dictKindSymbol:  {},
+	}
+	// reading dictionary built from embedded dictionaries only.
+	baseReadingDict map[string]string
+
+	regexpExtraReadingWord = regexp.MustCompile(`^[A-Z]+$`)
+	// words must not start with "#", which would be read as a comment line from the persisted file
+	regexpExtraReplaceWord = regexp.MustCompile(`^[^\s#][^\s]*$`)
+	regexpExtraDictReading = regexp.MustCompile(`^[ァ-ヶー]+$`)
+)
+
+// returns current reading dictionary, replace dictionary and symbol dictionary.
+// returned maps must not be modified.
+func currentDicts() (map[string]string, *wordReplacer, *symbolReplacer) {
+	dictsMu.RLock()
+	defer dictsMu.RUnlock()
+	return readingDict, replaceDict, symbolDict
+}
+
+// loads extra dictionaries from the directory, and merges them into readingDict/replaceDict/symbolDict.
+// entries of extra dictionaries take precedence of ones of embedded dictionaries.
+// missing dictionary files are treated as empty.
+func loadExtraDicts(dir string) error {
+	extraDictsMu.Lock()
+	defer extraDictsMu.Unlock()
+
+	if baseReadingDict == nil {
+		baseReadingDict, _, _ = currentDicts()
+	}
+
+	entries := make(map[dictKind]map[string]string, len(extraDictFileNames))
+	for kind, name := range extraDictFileNames {
+		f, err := os.Open(filepath.Join(dir, name))
+		if errors.Is(err, os.ErrNotExist) {
+			entries[kind] = make(map[string]string)
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to open extra dictionary file: %w", err)
+		}
+		es, err := parseExtraDictEntries(kind, f)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("failed to parse extra dictionary file %s: %w", name, err)
+		}
+		entries[kind] = es
+	}
+
+	extraDictDir = dir
+	extraDictEntries = entries
+	rebuildDicts()
+	return nil
+}
+
+func parseExtraDictEntries(kind dictKind, r io.Reader) (map[string]string, error) {
+	entries := make(map[string]string)
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if len(line) == 0 || line[0] == '#' {
+			continue
+		}
+		split := strings.Split(line, " ")
+		if len(split) < 2 {
+			continue
+		}
+		word, reading, err := normalizeExtraDictEntry(kind, split[0], split[1])
+		if err != nil {
+			return nil, err
+		}
+		entries[word] = reading
+	}
+	return entries, scanner.Err()
+}
+
+// validates the entry of extra dictionary and normalizes it to the format of dictionary files.
+func normalizeExtraDictEntry(kind dictKind, word, reading string) (string, string, error) {
+	word = normalizeExtraDictWord(kind, word)
+	reading = hiraganaToKatakana(strings.TrimSpace(reading))
+
+	switch kind {
+	case dictKindReading:
+		if !regexpExtraReadingWord.MatchString(word) {
+			return "", "", fmt.Errorf("invalid word %q: must consist of English alphabets", word)
+		}
+	case dictKindReplace:
+		if !regexpExtraReplaceWord.MatchString(word) {
+			return "", "", fmt.Errorf("invalid word %q: must not be empty, start with '#' or contain spaces", word)
+		}
+	case dictKindSymbol:
+		if !regexpExtraReplaceWord.MatchString(word) {
+			return "", "", fmt.Errorf("invalid symbol %q: must not be empty, start with '#' or contain spaces", word)
+		}
+	default:
+		return "", "", fmt.Errorf("unknown dictionary kind %q", kind)
+	}
+	if !regexpExtraDictReading.MatchString(reading) {
+		return "", "", fmt.Errorf("invalid reading %q: must consist of kana", reading)
+	}
+	return word, reading, nil
+}
+
+// words of symbol dictionary are case-sensitive (e.g. mL and ML), others are in upper case.
+func normalizeExtraDictWord(kind dictKind, word string) string {
+	word = strings.TrimSpace(word)
+	if kind == dictKindSymbol {
+		return word
+	}
+	return strings.ToUpper(word)
+}
+
+// adds (or overwrites) the entry to the extra dictionary, and persists it.
+func addExtraDictEntry(kind dictKind, word, reading string) error {
+	word, reading, err := normalizeExtraDictEntry(kind, word, reading)
+	if err != nil {
+		return err
+	}
+
+	extraDictsMu.Lock()
+	defer extraDictsMu.Unlock()
+
+	entries := maps.Clone(extraDictEntries[kind])
+	entries[word] = reading
+	return updateExtraDict(kind, entries)
+}
+
+// removes the entry from the extra dictionary, and persists it.
+// returns false if the entry doesn't exist.
+func removeExtraDictEntry(kind dictKind, word string) (bool, error) {
+	word = normalizeExtraDictWord(kind, word)
+
+	extraDictsMu.Lock()
+	defer extraDictsMu.Unlock()
+
+	if _, ok := extraDictEntries[kind][word]; !ok {
+		return false, nil
+	}
+	entries := maps.Clone(extraDictEntries[kind])
+	delete(entries, word)
+	return true, updateExtraDict(kind, entries)
+}
+
+// returns a copy of entries of the extra dictionary.
+func listExtraDictEntries(kind dictKind) map[string]string {
+	extraDictsMu.Lock()
+	defer extraDictsMu.Unlock()
+	return maps.Clone(extraDictEntries[kind])
+}
+
+// persists entries of the extra dictionary, then applies them.
+// entries are applied only if they are successfully persisted.
+//
+// pre-condition: extraDictsMu is locked
+func updateExtraDict(kind dictKind, entries map[string]string) error {
+	if err := saveExtraDict(kind, entries); err != nil {
+		return err
+	}
+	extraDictEntries[kind] = entries
+	rebuildDicts()
+	return nil
+}
+
+// writes entries of the extra dictionary to the file atomically.
+//
+// pre-condition: extraDictsMu is locked
+func saveExtraDict(kind dictKind, entries map[string]string) error {
+	if extraDictDir == "" {
+		return errors.New("extra dictionary directory is not configured")
+	}
+
+	f, err := os.CreateTemp(extraDictDir, extraDictFileNames[kind]+".tmp*")
+	if err != nil {
+		return fmt.Errorf("failed to create extra dictionary file: %w", err)
+	}
+	defer os.Remove(f.Name())
+
+	w := bufio.NewWriter(f)
+	_, _ = fmt.Fprintf(w, "# Extra %s dictionary, edited via admin API.\n", kind)
+	for _, word := range slices.Sorted(maps.Keys(entries)) {
+		_, _ = fmt.Fprintf(w, "%s %s\n", word, entries[word])
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write extra dictionary file: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write extra dictionary file: %w", err)
+	}
+	if err := os.Rename(f.Name(), filepath.Join(extraDictDir, extraDictFileNames[kind])); err != nil {
+		return fmt.Errorf("failed to write extra dictionary file: %w", err)
+	}
+	return nil
+}
+
+// builds readingDict/replaceDict/symbolDict from embedded dictionaries and extra dictionaries, then swaps them.
+//
+// pre-condition: extraDictsMu is locked
+func rebuildDicts() {
+	rd := maps.Clone(baseReadingDict)
+	maps.Copy(rd, extraDictEntries[dictKindReading])
+
+	// replaceDictEntries is never modified after initialization, so it can be used as the base
+	rpe := maps.Clone(replaceDictEntries)
+	for word, reading := range extraDictEntries[dictKindReplace] {
+		rpe[word] = naturalizeEnWordReading(reading)
+	}
+	rpd := newWordReplacer(rpe)
+
+	sde := maps.Clone(symbolDictEntries)
+	maps.Copy(sde, extraDictEntries[dictKindSymbol])
+	sd := newSymbolReplacer(sde)
+
+	dictsMu.Lock()
+	defer dictsMu.Unlock()
+	readingDict, replaceDict, symbolDict = rd, rpd, sd
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"log"
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// restores extra dictionaries, and dictionaries built from them, after the test.
+func restoreExtraDictsOnCleanup(t *testing.T) {
+	t.Helper()
+	extraDictsMu.Lock()
+	dir, entries := extraDictDir, maps.Clone(extraDictEntries)
+	extraDictsMu.Unlock()
+
+	t.Cleanup(func() {
+		extraDictsMu.Lock()
+		defer extraDictsMu.Unlock()
+		extraDictDir, extraDictEntries = dir, entries
+		rebuildDicts()
+	})
+}
+
+func TestExtraDicts(t *testing.T) {
+	if err := initialize(); err != nil {
+		log.Fatal(err)
+	}
+	restoreExtraDictsOnCleanup(t)
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "custom.dic"), []byte("# comment\nZORBLAX ぞるぶらっくす\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := loadExtraDicts(dir); err != nil {
+		t.Fatalf("loadExtraDicts returned error: %v", err)
+	}
+
+	if r, ok := getEnWordReading("ZORBLAX"); !ok || r != "ゾルブラックス" {
+		t.Errorf("getEnWordReading(ZORBLAX) = %q, %v; want loaded reading", r, ok)
+	}
+
+	// add entries
+	if err := addExtraDictEntry(dictKindReading, "Yomiapi", "ヨミエーピーアイ"); err != nil {
+		t.Fatalf("addExtraDictEntry returned error: %v", err)
+	}
+	if err := addExtraDictEntry(dictKindReplace, "xqzv", "クズブ"); err != nil {
+		t.Fatalf("addExtraDictEntry returned error: %v", err)
+	}
+	if r, ok := getEnWordReading("YOMIAPI"); !ok || r != "ヨミエーピーアイ" {
+		t.Errorf("getEnWordReading(YOMIAPI) = %q, %v; want added reading", r, ok)
+	}
+	if got := normalizeText("this is xqzv", analyzeOptions{}); !strings.Contains(got, "クズブ") {
+		t.Errorf("normalizeText = %q; want added replacement applied", got)
+	}
+
+	// entries are persisted
+	b, err := os.ReadFile(filepath.Join(dir, "custom.dic"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(b), "YOMIAPI ヨミエーピーアイ\n") || !strings.Contains(string(b), "ZORBLAX ゾルブラックス\n") {
+		t.Errorf("persisted reading dictionary = %q; want both entries", b)
+	}
+	if err := loadExtraDicts(dir); err != nil {
+		t.Fatalf("loadExtraDicts returned error: %v", err)
+	}
+	if got := listExtraDictEntries(dictKindReplace); got["XQZV"] != "クズブ" {
+		t.Errorf("reloaded replace dictionary = %v; want persisted entry", got)
+	}
+
+	// invalid entries are rejected
+	invalids := []struct {
+		kind    dictKind
+		word    string
+		reading string
+	}{
+		{kind: dictKindReading, word: "日本", reading: "ニホン"},
+		{kind: dictKindReading, word: "FOO", reading: "foo"},
+		{kind: dictKindReplace, word: "FOO BAR", reading: "フーバー"},
+		{kind: dictKindReplace, word: "", reading: "フー"},
+		{kind: "unknown", word: "FOO", reading: "フー"},
+	}
+	for _, tt := range invalids {
+		if err := addExtraDictEntry(tt.kind, tt.word, tt.reading); err == nil {
+			t.Errorf("addExtraDictEntry(%q, %q, %q) succeeded; want error", tt.kind, tt.word, tt.reading)
+		}
+	}
+
+	// remove entries
+	for _, e := range []struct {
+		kind dictKind
+		word string
+	}{{dictKindReading, "zorblax"}, {dictKindReading, "YOMIAPI"}, {dictKindReplace, "XQZV"}} {
+		if ok, err := removeExtraDictEntry(e.kind, e.word); !ok || err != nil {
+			t.Errorf("removeExtraDictEntry(%q, %q) = %v, %v; want true, nil", e.kind, e.word, ok, err)
+		}
+	}
+	if ok, err := removeExtraDictEntry(dictKindReading, "ZORBLAX"); ok || err != nil {
+		t.Errorf("removeExtraDictEntry for removed entry = %v, %v; want false, nil", ok, err)
+	}
+	if _, ok := getEnWordReading("ZORBLAX"); ok {
+		t.Errorf("getEnWordReading(ZORBLAX) found removed entry")
+	}
+	if got := normalizeText("this is xqzv", analyzeOptions{}); strings.Contains(got, "クズブ") {
+		t.Errorf("normalizeText = %q; want removed replacement not applied", got)
+	}
+}
+
+func TestExtraDicts_overrideEmbedded(t *testing.T) {
+	if err := initialize(); err != nil {
+		log.Fatal(err)
+	}
+	restoreExtraDictsOnCleanup(t)
+	if err := loadExtraDicts(t.TempDir()); err != nil {
+		t.Fatalf("loadExtraDicts returned error: %v", err)
+	}
+
+	if err := addExtraDictEntry(dictKindReplace, "YOU'VE", "ユーヴ"); err != nil {
+		t.Fatalf("addExtraDictEntry returned error: %v", err)
+	}
+	if got := normalizeText("you've", analyzeOptions{}); got != "ユーヴ" {
+		t.Errorf("normalizeText(you've) = %q; want overridden replacement", got)
+	}
+
+	if _, err := removeExtraDictEntry(dictKindReplace, "YOU'VE"); err != nil {
+		t.Fatalf("removeExtraDictEntry returned error: %v", err)
+	}
+	if got := normalizeText("you've", analyzeOptions{}); got != "ユーブ" {
+		t.Errorf("normalizeText(you've) = %q; want embedded replacement", got)
+	}
+}
+
+func TestExtraDicts_symbol(t *testing.T) {
+	if err := initialize(); err != nil {
+		log.Fatal(err)
+	}
+	restoreExtraDictsOnCleanup(t)
+	if err := loadExtraDicts(t.TempDir()); err != nil {
+		t.Fatalf("loadExtraDicts returned error: %v", err)
+	}
+
+	// words of symbol dictionary are case-sensitive
+	if err := addExtraDictEntry(dictKindSymbol, "mph", "マイル"); err != nil {
+		t.Fatalf("addExtraDictEntry returned error: %v", err)
+	}
+	if got := listExtraDictEntries(dictKindSymbol); got["mph"] != "マイル" {
+		t.Errorf("symbol dictionary = %v; want entry in original case", got)
+	}
+	if got := normalizeText("60mph", analyzeOptions{normalization: normalizationFull}); got != "ロクジュウマイル" {
+		t.Errorf("normalizeText(60mph) = %q; want added unit applied", got)
+	}
+
+	if ok, err := removeExtraDictEntry(dictKindSymbol, "mph"); !ok || err != nil {
+		t.Fatalf("removeExtraDictEntry = %v, %v; want true, nil", ok, err)
+	}
+	if got := normalizeText("60mph", analyzeOptions{normalization: normalizationFull}); strings.Contains(got, "マイル") {
+		t.Errorf("normalizeText(60mph) = %q; want removed unit not applied", got)
+	}
+}
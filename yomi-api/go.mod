@@ -3,8 +3,14 @@ module yomi-api
 go 1.24.0
 
 require (
+	github.com/ikawaha/kagome-dict v1.1.7
 	github.com/ikawaha/kagome-dict-ipa-neologd v0.3.2
+	github.com/ikawaha/kagome-dict/ipa v1.2.6
+	github.com/ikawaha/kagome-dict/uni v1.2.6
 	github.com/ikawaha/kagome/v2 v2.10.3
 )
 
-require github.com/ikawaha/kagome-dict v1.1.7 // indirect
+require (
+	github.com/rivo/uniseg v0.4.7
+	golang.org/x/text v0.32.0
+)
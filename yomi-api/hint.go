@@ -0,0 +1,129 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/ikawaha/kagome/v2/tokenizer"
+)
+
+var errInvalidReadingHint = errors.New("invalid reading hint")
+
+var regexpReadingHint = regexp.MustCompile(`^[ぁ-ゖァ-ヶー 　]+$`)
+
+// max number of reading candidates considered per token
+const maxReadingCandidatesPerToken = 32
+
+// checks that the reading hint is plausible for the tokens, and assigns a part of the hint to each token.
+//
+// the hint is plausible if it can be split into readings of each token, where:
+//   - kana in the text must be read as is
+//   - words consisting of kanji etc. must be read in one of readings in the dictionary
+//   - English words must be read in the dictionary reading or literal reading of alphabets
+//
+// resulting readings are normalized to fullwidth katakana.
+func alignReadingHint(tokens []tokenizer.Token, hint string, opts analyzeOptions) ([]string, error) {
+	hint = normalizeUnicode(hint)
+	if !regexpReadingHint.MatchString(hint) {
+		return nil, fmt.Errorf("%w: reading hint must consist of katakana", errInvalidReadingHint)
+	}
+	h := normalizeKanaString(opts.expandLongVowels(hint))
+	if h == "" {
+		return nil, fmt.Errorf("%w: reading hint is empty", errInvalidReadingHint)
+	}
+
+	// reach[i]: possible positions (in bytes) in the hint after reading tokens[:i], mapped to the reading of tokens[i-1] leading to there and the position before it
+	type step struct {
+		prev    int
+		reading string
+	}
+	reach := make([]map[int]step, len(tokens)+1)
+	reach[0] = map[int]step{0: {}}
+
+	for i, t := range tokens {
+		reach[i+1] = make(map[int]step)
+		cands := readingCandidatesOfToken(t, opts)
+		// iterate in fixed order and keep the first path found, to make the result deterministic
+		for _, pos := range slices.Sorted(maps.Keys(reach[i])) {
+			for _, c := range cands {
+				n, ok := matchReadingPrefix(h[pos:], c)
+				if _, found := reach[i+1][pos+n]; ok && !found {
+					reach[i+1][pos+n] = step{prev: pos, reading: h[pos : pos+n]}
+				}
+			}
+		}
+		if len(reach[i+1]) == 0 {
+			return nil, fmt.Errorf("%w: reading hint doesn't match the content around %q", errInvalidReadingHint, t.Surface)
+		}
+	}
+	if _, ok := reach[len(tokens)][len(h)]; !ok {
+		return nil, fmt.Errorf("%w: reading hint is longer than the reading of the content", errInvalidReadingHint)
+	}
+
+	// trace back the path from the end of the hint
+	res := make([]string, len(tokens))
+	pos := len(h)
+	for i := len(tokens); i > 0; i-- {
+		st := reach[i][pos]
+		res[i-1] = st.reading
+		pos = st.prev
+	}
+	return res, nil
+}
+
+// returns possible readings of the token, normalized to fullwidth katakana.
+// result may include empty string, which means the token has no reading (e.g. symbols).
+func readingCandidatesOfToken(t tokenizer.Token, opts analyzeOptions) []string {
+	r, _ := kanaReadingOfToken(t, opts)
+	cands := []string{r}
+
+	// kana and words in the user dictionary must be read as is
+	if isUserDictToken(t) || regexpFwKanaWord.MatchString(t.Surface) || regexpAllHwKana.MatchString(t.Surface) {
+		return cands
+	}
+
+	add := func(r string) {
+		if !slices.Contains(cands, r) {
+			cands = append(cands, r)
+		}
+	}
+	if regexpAllEnAlphabet.MatchString(t.Surface) && opts.enFallback != enFallbackNone {
+		add(normalizeKanaString(opts.expandLongVowels(spellEnWord(strings.ToUpper(t.Surface)))))
+	}
+	for _, r := range surfaceReadingCandidates(t.Surface, maxReadingCandidatesPerToken) {
+		add(r)
+	}
+	return cands
+}
+
+// checks if the hint starts with the reading, and returns the length of the matched part of the hint.
+// long vowel marks in the reading may be omitted in the hint (e.g. "コヒ" matches "コーヒー"),
+// while ones in the hint are accepted only in place of long vowels of the reading (e.g. "トーキョー" matches "トウキョウ", but "スシー" doesn't match "スシ").
+func matchReadingPrefix(hint, reading string) (int, bool) {
+	i := 0
+	prev := rune(0)
+	for _, r := range reading {
+		switch {
+		case strings.HasPrefix(hint[i:], string(r)):
+			i += len(string(r))
+		case strings.HasPrefix(hint[i:], "ー") && isLongVowelOf(prev, r):
+			i += len("ー")
+		case r != 'ー':
+			return 0, false
+		}
+		if r != 'ー' {
+			prev = r
+		}
+	}
+	return i, true
+}
+
+// checks if the kana lengthens the vowel of the preceding kana, so that it can be written as a long vowel mark (e.g. ウ after ト, イ after エ).
+func isLongVowelOf(prev, k rune) bool {
+	v, ok := kanaVowels[prev]
+	return ok && (k == v || v == 'オ' && k == 'ウ' || v == 'エ' && k == 'イ')
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"errors"
+	"log"
+	"slices"
+	"testing"
+)
+
+func TestAnalyzeHeadAndLast_readingHint(t *testing.T) {
+	if err := initialize(); err != nil {
+		log.Fatal(err)
+	}
+
+	tests := []struct {
+		in      string
+		hint    string
+		romaji  romajiDetection
+		wantErr bool
+		head    rune
+		last    rune
+	}{
+		{in: "日本", hint: "ニッポン", head: 'ニ', last: 'ン'},
+		{in: "日本", hint: "ニホン", head: 'ニ', last: 'ン'},
+		{in: "日本へ行こう！", hint: "ニッポンヘイコウ", head: 'ニ', last: 'ウ'},
+		{in: "上手", hint: "カミテ", head: 'カ', last: 'テ'},
+		{in: "今日", hint: "コンニチ", head: 'コ', last: 'チ'},
+		{in: "ostrich", hint: "オーエスティーアールアイシーエイチ", head: 'オ', last: 'チ'},
+		{in: "今日は寿司", hint: "こんにちはすし", head: 'コ', last: 'シ'},
+		// romaji words are read as romaji only if romaji detection is enabled
+		{in: "arigatou", hint: "アリガトー", wantErr: true},
+		{in: "arigatou", hint: "アリガトー", romaji: romajiDetectionStrict, head: 'ア', last: 'ト'},
+		{in: "日本", hint: "ニホンゴ", wantErr: true},
+		{in: "日本", hint: "ヤマト", wantErr: true},
+		{in: "ねこ", hint: "イヌ", wantErr: true},
+		{in: "日本", hint: "nihon", wantErr: true},
+		{in: "xqzv", hint: "クズブ", wantErr: true},
+		// long vowel marks are accepted only where the reading has long vowels
+		{in: "東京", hint: "トーキョー", head: 'ト', last: 'ョ'},
+		{in: "寿司", hint: "スシー", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		hl, err := analyzeHeadAndLast(tt.in, analyzeOptions{readingHint: tt.hint, romaji: tt.romaji})
+		if tt.wantErr {
+			if !errors.Is(err, errInvalidReadingHint) {
+				t.Errorf("analyzeHeadAndLast(%q) with hint %q got %v; want invalid reading hint error", tt.in, tt.hint, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("analyzeHeadAndLast(%q) with hint %q got unexpected error: %v", tt.in, tt.hint, err)
+			continue
+		}
+		if hl.head != tt.head || hl.last != tt.last {
+			t.Errorf("analyzeHeadAndLast(%q) with hint %q = %q, %q; want %q, %q", tt.in, tt.hint, hl.head, hl.last, tt.head, tt.last)
+		}
+	}
+}
+
+func TestMatchReadingPrefix(t *testing.T) {
+	tests := []struct {
+		hint    string
+		reading string
+		want    int
+		wantOk  bool
+	}{
+		{hint: "ラーメン", reading: "ラーメン", want: len("ラーメン"), wantOk: true},
+		{hint: "コーヒーギュウニュウ", reading: "コーヒー", want: len("コーヒー"), wantOk: true},
+		// long vowel marks in the reading may be omitted
+		{hint: "コヒ", reading: "コーヒー", want: len("コヒ"), wantOk: true},
+		// long vowel marks in the hint are accepted only in place of long vowels
+		{hint: "トーキョー", reading: "トウキョウ", want: len("トーキョー"), wantOk: true},
+		{hint: "エーガ", reading: "エイガ", want: len("エーガ"), wantOk: true},
+		{hint: "オカーサン", reading: "オカアサン", want: len("オカーサン"), wantOk: true},
+		{hint: "ラーメン", reading: "ラメン", wantOk: false},
+		{hint: "スーシ", reading: "スシ", wantOk: false},
+		{hint: "スシー", reading: "スシ", want: len("スシ"), wantOk: true},
+		{hint: "ラーメン", reading: "", want: 0, wantOk: true},
+		{hint: "ラーメン", reading: "ラム", wantOk: false},
+		{hint: "ラ", reading: "ラメン", wantOk: false},
+	}
+
+	for _, tt := range tests {
+		got, ok := matchReadingPrefix(tt.hint, tt.reading)
+		if got != tt.want || ok != tt.wantOk {
+			t.Errorf("matchReadingPrefix(%q, %q) = %d, %v; want %d, %v", tt.hint, tt.reading, got, ok, tt.want, tt.wantOk)
+		}
+	}
+}
+
+func TestEffectiveHeadAndLastMorae_readingHint(t *testing.T) {
+	if err := initialize(); err != nil {
+		log.Fatal(err)
+	}
+
+	// morae of hinted readings keep long vowel marks, just like ones of readings in the dictionary
+	hl, err := analyzeHeadAndLast("珈琲", analyzeOptions{readingHint: "コーヒー"})
+	if err != nil {
+		t.Fatalf("analyzeHeadAndLast returned error: %v", err)
+	}
+	head, last := hl.morae(2, analyzeOptions{})
+	if !slices.Equal(head, []string{"コー", "ヒー"}) || !slices.Equal(last, []string{"コー", "ヒー"}) {
+		t.Errorf("morae of 珈琲 with hint コーヒー = %q, %q; want [コー ヒー], [コー ヒー]", head, last)
+	}
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	ipaneologd "github.com/ikawaha/kagome-dict-ipa-neologd"
+	"github.com/ikawaha/kagome-dict/dict"
+	"github.com/ikawaha/kagome-dict/ipa"
+	"github.com/ikawaha/kagome-dict/uni"
+	"github.com/ikawaha/kagome/v2/tokenizer"
+)
+
+// name of kagome system dictionary.
+type kagomeDictName string
+
+const (
+	kagomeDictIPA        kagomeDictName = "ipa"
+	kagomeDictIPANeologd kagomeDictName = "ipa-neologd"
+	kagomeDictUni        kagomeDictName = "uni"
+)
+
+var kagomeDictLoaders = map[kagomeDictName]func() *dict.Dict{
+	kagomeDictIPA:        ipa.Dict,
+	kagomeDictIPANeologd: ipaneologd.Dict,
+	kagomeDictUni:        uni.Dict,
+}
+
+var (
+	// system dictionary and tokenize mode used for analysis.
+	tokenizerDictName = kagomeDictIPANeologd
+	tokenizeMode      = tokenizer.Normal
+
+	// tokenizers with other dictionaries, used for comparison in the trace endpoint. they are created on first use.
+	comparisonTokenizersMu sync.Mutex
+	comparisonTokenizers   = make(map[kagomeDictName]*tokenizer.Tokenizer)
+)
+
+// loads the system dictionary of the name.
+func loadKagomeDict(name kagomeDictName) (*dict.Dict, error) {
+	load, ok := kagomeDictLoaders[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown dictionary %q", name)
+	}
+	d := load()
+
+	// UniDic doesn't tell which feature is reading, and its 読み (lForm) is the reading of the lemma (e.g. イク for 行こう).
+	// so replace lForm with the reading of the word as written, and point Token.Reading() to it.
+	if name == kagomeDictUni {
+		if _, ok := d.ContentsMeta[dict.ReadingIndex]; !ok {
+			for id := range d.Contents {
+				replaceUniLFormWithSurfaceReading(d, id)
+			}
+			d.ContentsMeta[dict.ReadingIndex] = uni.LForm
+		}
+	}
+	return d, nil
+}
+
+// replaces 読み (lForm) of the UniDic word with the reading of the word as written.
+func replaceUniLFormWithSurfaceReading(d *dict.Dict, id int) {
+	off := len(d.POSTable.POSs[id])
+	c := d.Contents[id]
+	if len(c) <= uni.PronBase-off {
+		return
+	}
+	c[uni.LForm-off] = uniSurfaceReading(c[uni.LForm-off], c[uni.Orth-off], c[uni.OrthBase-off], c[uni.Pron-off], c[uni.PronBase-off])
+}
+
+// returns the reading of the UniDic word as written.
+// 読み (lForm) is used for the stem if it is the reading of the same form as 発音形基本形 (pronBase) (i.e. not of another lemma like 行く for ゆく),
+// and the inflected ending is taken from 発音形出現形 (pron), where long vowel marks are read as kana in 書字形出現形 (orth) if the ending of orth is written in kana.
+// e.g. 行こう (lForm: イク, pron: イコー, pronBase: イク) -> イコウ, 来 (lForm: クル, pron: キ, pronBase: クル) -> キ
+func uniSurfaceReading(lForm, orth, orthBase, pron, pronBase string) string {
+	if lForm == "*" || pron == "*" || pronBase == "*" {
+		return lForm
+	}
+	p, pb, base := []rune(pron), []rune(pronBase), []rune(pronBase)
+	if l := []rune(lForm); isUniPronOf(pb, l) {
+		base = l
+	}
+	n := commonPrefixLen(p, pb)
+	ending := string(p[n:])
+	if strings.Contains(ending, "ー") {
+		o, ob := []rune(orth), []rune(orthBase)
+		if oe := o[commonPrefixLen(o, ob):]; len(oe) == len(p)-n && regexpAllFwKana.MatchString(string(oe)) {
+			ending = hiraganaToKatakana(string(oe))
+		}
+	}
+	return string(base[:n]) + ending
+}
+
+// pairs of kana in 発音形 and 読み of UniDic which are pronounced differently from written (e.g. ワ for は).
+var uniPronChanges = map[rune]rune{'オ': 'ヲ', 'ワ': 'ハ', 'エ': 'ヘ', 'ズ': 'ヅ', 'ジ': 'ヂ'}
+
+// reports whether pron is the pronunciation of the reading, i.e. they differ only in long vowels and particles.
+func isUniPronOf(pron, reading []rune) bool {
+	if len(pron) != len(reading) {
+		return false
+	}
+	for i, r := range pron {
+		if r != reading[i] && r != 'ー' && uniPronChanges[r] != reading[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func commonPrefixLen(a, b []rune) int {
+	n := 0
+	for n < len(a) && n < len(b) && a[n] == b[n] {
+		n++
+	}
+	return n
+}
+
+func parseTokenizeMode(s string) (tokenizer.TokenizeMode, error) {
+	switch s {
+	case "normal":
+		return tokenizer.Normal, nil
+	case "search":
+		return tokenizer.Search, nil
+	case "extended":
+		return tokenizer.Extended, nil
+	}
+	return 0, fmt.Errorf("unknown tokenize mode %q", s)
+}
+
+// tokenizes the text with the configured dictionary and mode.
+func tokenize(s string) []tokenizer.Token {
+	return currentTokenizer().Analyze(s, tokenizeMode)
+}
+
+// returns tokenize mode for obtaining another segmentation than the configured mode.
+func alternateTokenizeMode() tokenizer.TokenizeMode {
+	if tokenizeMode == tokenizer.Search {
+		return tokenizer.Normal
+	}
+	return tokenizer.Search
+}
+
+// returns the tokenizer with the dictionary of the name.
+func tokenizerOf(name kagomeDictName) (*tokenizer.Tokenizer, error) {
+	if name == tokenizerDictName {
+		return currentTokenizer(), nil
+	}
+
+	comparisonTokenizersMu.Lock()
+	defer comparisonTokenizersMu.Unlock()
+
+	if t, ok := comparisonTokenizers[name]; ok {
+		return t, nil
+	}
+	d, err := loadKagomeDict(name)
+	if err != nil {
+		return nil, err
+	}
+	t, err := newKagomeTokenizer(d)
+	if err != nil {
+		return nil, err
+	}
+	comparisonTokenizers[name] = t
+	return t, nil
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"log"
+	"testing"
+
+	"github.com/ikawaha/kagome/v2/tokenizer"
+)
+
+func TestParseTokenizeMode(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    tokenizer.TokenizeMode
+		wantErr bool
+	}{
+		{in: "normal", want: tokenizer.Normal},
+		{in: "search", want: tokenizer.Search},
+		{in: "extended", want: tokenizer.Extended},
+		{in: "Normal", wantErr: true},
+		{in: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseTokenizeMode(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("parseTokenizeMode(%q) = %v, %v; want %v (error: %v)", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestTokenizerOf(t *testing.T) {
+	if err := initialize(); err != nil {
+		log.Fatal(err)
+	}
+
+	if _, err := tokenizerOf("unknown"); err == nil {
+		t.Errorf("tokenizerOf(unknown) succeeded; want error")
+	}
+	if tok, err := tokenizerOf(tokenizerDictName); err != nil || tok != currentTokenizer() {
+		t.Errorf("tokenizerOf(%q) = %p, %v; want current tokenizer", tokenizerDictName, tok, err)
+	}
+
+	tests := []struct {
+		dict kagomeDictName
+		in   string
+		head rune
+		last rune
+	}{
+		{dict: kagomeDictIPA, in: "公園に行く", head: 'コ', last: 'ク'},
+		{dict: kagomeDictUni, in: "公園に行く", head: 'コ', last: 'ク'},
+		{dict: kagomeDictUni, in: "りんごだよ！", head: 'リ', last: 'ヨ'},
+		{dict: kagomeDictUni, in: "東京", head: 'ト', last: 'ウ'},
+		// inflected words are read as written, not as their lemma
+		{dict: kagomeDictUni, in: "行こう", head: 'イ', last: 'ウ'},
+		{dict: kagomeDictUni, in: "書か", head: 'カ', last: 'カ'},
+		{dict: kagomeDictUni, in: "食べれ", head: 'タ', last: 'レ'},
+		{dict: kagomeDictUni, in: "来た", head: 'キ', last: 'タ'},
+	}
+	for _, tt := range tests {
+		resp := traceReadingWith(tt.in, analyzeOptions{}, tt.dict, mustTokenizerOf(t, tt.dict))
+		if resp.Dict != string(tt.dict) || !resp.Readable || resp.Head != tt.head || resp.Last != tt.last {
+			t.Errorf("traceReadingWith(%q) with %s = %+v; want %c, %c", tt.in, tt.dict, resp, tt.head, tt.last)
+		}
+	}
+
+	// UniDic names symbols 補助記号, which should be skipped as well as 記号 of IPA dictionary
+	tokens := mustTokenizerOf(t, kagomeDictUni).Tokenize("りんごだよ！")
+	hl, err := pickHeadAndLast(tokens, nil, analyzeOptions{skipParticles: true})
+	if err != nil || hl.last != 'ゴ' {
+		t.Errorf("pickHeadAndLast with UniDic and skipParticles = %+v, %v; want last ゴ", hl, err)
+	}
+}
+
+func mustTokenizerOf(t *testing.T, name kagomeDictName) *tokenizer.Tokenizer {
+	tok, err := tokenizerOf(name)
+	if err != nil {
+		t.Fatalf("tokenizerOf(%q) returned error: %v", name, err)
+	}
+	return tok
+}
+
+func TestUniSurfaceReading(t *testing.T) {
+	tests := []struct {
+		lForm, orth, orthBase, pron, pronBase string
+		want                                  string
+	}{
+		{lForm: "トウキョウ", orth: "東京", orthBase: "東京", pron: "トーキョー", pronBase: "トーキョー", want: "トウキョウ"},
+		{lForm: "イク", orth: "行こう", orthBase: "行く", pron: "イコー", pronBase: "イク", want: "イコウ"},
+		{lForm: "イク", orth: "行っ", orthBase: "行く", pron: "イッ", pronBase: "イク", want: "イッ"},
+		{lForm: "カク", orth: "書か", orthBase: "書く", pron: "カカ", pronBase: "カク", want: "カカ"},
+		{lForm: "タベル", orth: "食べれ", orthBase: "食べる", pron: "タベレ", pronBase: "タベル", want: "タベレ"},
+		{lForm: "オモウ", orth: "思おう", orthBase: "思う", pron: "オモオー", pronBase: "オモウ", want: "オモオウ"},
+		{lForm: "クル", orth: "来", orthBase: "来る", pron: "キ", pronBase: "クル", want: "キ"},
+		{lForm: "タベル", orth: "食べれ", orthBase: "食べれる", pron: "タベレ", pronBase: "タベレル", want: "タベレ"},
+		{lForm: "イク", orth: "ゆく", orthBase: "ゆく", pron: "ユク", pronBase: "ユク", want: "ユク"},
+		{lForm: "ハ", orth: "は", orthBase: "は", pron: "ワ", pronBase: "ワ", want: "ハ"},
+	}
+
+	for _, tt := range tests {
+		if got := uniSurfaceReading(tt.lForm, tt.orth, tt.orthBase, tt.pron, tt.pronBase); got != tt.want {
+			t.Errorf("uniSurfaceReading(%q, %q, %q, %q, %q) = %q; want %q", tt.lForm, tt.orth, tt.orthBase, tt.pron, tt.pronBase, got, tt.want)
+		}
+	}
+}
@@ -8,10 +8,15 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
 	"regexp"
+	"strconv"
 	"strings"
+	"syscall"
 
-	ipaneologd "github.com/ikawaha/kagome-dict-ipa-neologd"
+	"github.com/ikawaha/kagome-dict/dict"
 	"github.com/ikawaha/kagome/v2/tokenizer"
 )
 
@@ -20,8 +25,14 @@ var (
 	dicts embed.FS
 
 	readingDict = make(map[string]string)
-	replaceDict = make(map[*regexp.Regexp]string)
-
+	// entries of embedded replace dictionary (word in upper case -> reading), compiled into replaceDict
+	replaceDictEntries = make(map[string]string)
+	replaceDict        = newWordReplacer(nil)
+	// entries of embedded symbol dictionary (symbol or unit -> reading), compiled into symbolDict
+	symbolDictEntries = make(map[string]string)
+	symbolDict        = newSymbolReplacer(nil)
+
+	kagomeDict      *dict.Dict
 	kagomeTokenizer *tokenizer.Tokenizer
 )
 
@@ -64,17 +75,43 @@ func parseReplaceDict(path string) error {
 		if len(split) < 2 {
 			continue
 		}
-		re := regexp.MustCompile(fmt.Sprintf(`\b(?i:%s)\b`, split[0]))
-		replaceDict[re] = naturalizeEnWordReading(split[1])
+		replaceDictEntries[strings.ToUpper(split[0])] = naturalizeEnWordReading(split[1])
 	}
+	replaceDict = newWordReplacer(replaceDictEntries)
+	return nil
+}
+
+func parseSymbolDict(path string) error {
+	f, err := dicts.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open dictionary file: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if len(line) == 0 || line[0] == '#' {
+			continue
+		}
+		split := strings.Split(line, " ")
+		if len(split) < 2 {
+			continue
+		}
+		symbolDictEntries[split[0]] = split[1]
+	}
+	symbolDict = newSymbolReplacer(symbolDictEntries)
 	return nil
 }
 
 func initialize() error {
 	var err error
-	kagomeTokenizer, err = tokenizer.New(ipaneologd.Dict(), tokenizer.OmitBosEos())
+	if kagomeDict, err = loadKagomeDict(tokenizerDictName); err != nil {
+		return err
+	}
+	kagomeTokenizer, err = newKagomeTokenizer(kagomeDict)
 	if err != nil {
-		return fmt.Errorf("failed to initialize kagome tokenizer: %w", err)
+		return err
 	}
 
 	if err := parseReadingDict("dicts/bep-eng.dic"); err != nil {
@@ -87,17 +124,76 @@ func initialize() error {
 	if err := parseReplaceDict("dicts/replace.dic"); err != nil {
 		return err
 	}
+	if err := parseSymbolDict("dicts/symbol.dic"); err != nil {
+		return err
+	}
+	if err := parseEmojiAnnotations("dicts/emoji-ja.json"); err != nil {
+		return err
+	}
 	return nil
 }
 
 func main() {
+	if d := os.Getenv("TOKENIZER_DICT"); d != "" {
+		tokenizerDictName = kagomeDictName(d)
+	}
+	if m := os.Getenv("TOKENIZE_MODE"); m != "" {
+		var err error
+		if tokenizeMode, err = parseTokenizeMode(m); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if d := os.Getenv("ROMAJI_DETECTION"); d != "" {
+		var err error
+		if romajiDetectionMode, err = parseRomajiDetection(d); err != nil {
+			log.Fatal(err)
+		}
+	}
+	userDictPath = os.Getenv("USER_DICT_PATH")
 	if err := initialize(); err != nil {
 		log.Fatal(err)
 	}
+	adminToken = os.Getenv("ADMIN_TOKEN")
+
+	// extra dictionaries editable at runtime
+	if dir := os.Getenv("EXTRA_DICT_DIR"); dir != "" {
+		if err := loadExtraDicts(dir); err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("loaded extra dictionaries from %s", dir)
+
+		if adminToken != "" {
+			http.HandleFunc("/admin/dict", handleAdminDict)
+		}
+	}
+
+	// user dictionary can be reloaded by SIGHUP or admin endpoint
+	if userDictPath != "" {
+		log.Printf("loaded user dictionary from %s", userDictPath)
+
+		hup := make(chan os.Signal, 1)
+		signal.Notify(hup, syscall.SIGHUP)
+		go func() {
+			for range hup {
+				if err := reloadUserDict(); err != nil {
+					log.Printf("failed to reload user dictionary: %v", err)
+					continue
+				}
+				log.Print("reloaded user dictionary")
+			}
+		}()
+
+		if adminToken != "" {
+			http.HandleFunc("/admin/userdict/reload", handleAdminReloadUserDict)
+		}
+	}
 
 	http.HandleFunc("/", handleHeadLastKana)
+	http.HandleFunc("/v1/analyze", handleAnalyze)
+	http.HandleFunc("/v1/trace", handleTrace)
 	http.HandleFunc("/health", handleHealth)
 
+	log.Printf("using dictionary %s (mode: %v)", tokenizerDictName, tokenizeMode)
 	log.Print("listening on :8080")
 	if err := http.ListenAndServe(":8080", nil); err != nil {
 		log.Fatal(err)
@@ -105,9 +201,20 @@ func main() {
 }
 
 type HeadLastKanaResp struct {
-	Readable bool `json:"readable"`
-	Head     rune `json:"head,omitempty"`
-	Last     rune `json:"last,omitempty"`
+	Readable    bool     `json:"readable"`
+	Head        rune     `json:"head,omitempty"`
+	Last        rune     `json:"last,omitempty"`
+	HeadMorae   []string `json:"headMorae,omitempty"`
+	LastMorae   []string `json:"lastMorae,omitempty"`
+	HeadPOS     []string `json:"headPos,omitempty"`
+	LastPOS     []string `json:"lastPos,omitempty"`
+	HeadSurface string   `json:"headSurface,omitempty"`
+	LastSurface string   `json:"lastSurface,omitempty"`
+	Reason      string   `json:"reason,omitempty"`
+	HintError   string   `json:"hintError,omitempty"`
+
+	HeadCandidates []rune `json:"headCandidates,omitempty"`
+	LastCandidates []rune `json:"lastCandidates,omitempty"`
 }
 
 func handleHeadLastKana(w http.ResponseWriter, r *http.Request) {
@@ -116,24 +223,110 @@ func handleHeadLastKana(w http.ResponseWriter, r *http.Request) {
 		_, _ = fmt.Fprintf(w, "method not allowed")
 		return
 	}
+	serveHeadLastKana(w, r, parseAnalyzeOptions)
+}
 
+// analyzes a content (GET) or multiple contents at once (POST).
+// unlike the legacy endpoint, options for normalization and extraction can be specified per request.
+func handleAnalyze(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		serveHeadLastKana(w, r, parseV1AnalyzeOptions)
+	case http.MethodPost:
+		handleAnalyzeBatch(w, r)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		_, _ = fmt.Fprintf(w, "method not allowed")
+	}
+}
+
+// analyzes the content specified by the query parameter "c", with options parsed by parseOpts.
+func serveHeadLastKana(w http.ResponseWriter, r *http.Request, parseOpts func(url.Values) (analyzeOptions, error)) {
+	opts, err := parseOpts(r.URL.Query())
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = fmt.Fprint(w, err.Error())
+		return
+	}
 	content := r.URL.Query().Get("c")
-	head, last, err := effectiveHeadAndLast(content)
+	opts.readingHint = r.URL.Query().Get("reading")
+	opts.customEmojis = r.URL.Query()["emojiTag"]
 
-	var resp HeadLastKanaResp
+	resp, err := headLastKanaResp(content, opts)
 	if err != nil {
 		log.Printf("failed to determine head/last of reading of content(%q) %v", content, err)
-		resp.Readable = false
-	} else {
-		resp.Readable = true
-		resp.Head = head
-		resp.Last = last
+	} else if opts.readingHint != "" {
+		log.Printf("accepted reading hint %q for content(%q)", opts.readingHint, content)
 	}
 	jenc := json.NewEncoder(w)
 	jenc.SetIndent("", "")
 	_ = jenc.Encode(resp)
 }
 
+// parses options for analysis from query parameters of the legacy endpoint.
+func parseAnalyzeOptions(q url.Values) (analyzeOptions, error) {
+	var opts analyzeOptions
+
+	// number of morae of head/last to return (for multi-mora shiritori)
+	if nq := q.Get("n"); nq != "" {
+		n, err := strconv.Atoi(nq)
+		if err != nil || n < 1 {
+			return opts, errors.New("invalid number of morae")
+		}
+		opts.moraCount = n
+	}
+	if sp := q.Get("skipParticles"); sp != "" {
+		var err error
+		if opts.skipParticles, err = strconv.ParseBool(sp); err != nil {
+			return opts, errors.New("invalid skipParticles")
+		}
+	}
+	if cq := q.Get("candidates"); cq != "" {
+		var err error
+		if opts.withCandidates, err = strconv.ParseBool(cq); err != nil {
+			return opts, errors.New("invalid candidates")
+		}
+	}
+	// whether to read custom emoji shortcodes declared in emoji tags (opt-in, so that the legacy behavior is kept by default)
+	if ce := q.Get("customEmoji"); ce != "" {
+		var err error
+		if opts.readCustomEmoji, err = strconv.ParseBool(ce); err != nil {
+			return opts, errors.New("invalid customEmoji")
+		}
+	}
+	return opts, nil
+}
+
+// analyzes the content and build the response.
+// if the analysis fails, returns "not readable" response along with the error.
+func headLastKanaResp(content string, opts analyzeOptions) (HeadLastKanaResp, error) {
+	hl, err := analyzeHeadAndLast(content, opts)
+	if err != nil {
+		resp := HeadLastKanaResp{Readable: false, Reason: string(unreadableReasonOf(err))}
+		if errors.Is(err, errInvalidReadingHint) {
+			resp.HintError = err.Error()
+		}
+		return resp, err
+	}
+
+	resp := HeadLastKanaResp{
+		Readable:    true,
+		Head:        hl.head,
+		Last:        hl.last,
+		HeadPOS:     hl.headToken().POS(),
+		LastPOS:     hl.lastToken().POS(),
+		HeadSurface: hl.headToken().Surface,
+		LastSurface: hl.lastToken().Surface,
+	}
+	if opts.moraCount > 0 {
+		resp.HeadMorae, resp.LastMorae = hl.morae(opts.moraCount, opts)
+	}
+	if opts.withCandidates {
+		resp.HeadCandidates, resp.LastCandidates = hl.candidates(opts)
+	}
+	return resp, nil
+}
+
 func handleHealth(w http.ResponseWriter, r *http.Request) {
 	log.Print("health checked")
 	_, _ = fmt.Fprintf(w, "ok")
@@ -141,8 +334,111 @@ func handleHealth(w http.ResponseWriter, r *http.Request) {
 
 // returns head and last kana of reading of the text. resulting kana will be normalized to fullwith katakana.
 func effectiveHeadAndLast(s string) (rune, rune, error) {
-	normalized := normalizeText(s)
-	tokens := kagomeTokenizer.Tokenize(normalized)
+	hl, err := analyzeHeadAndLast(s, analyzeOptions{})
+	if err != nil {
+		return 0, 0, err
+	}
+	return hl.head, hl.last, nil
+}
+
+// options for analysis of head/last of reading.
+type analyzeOptions struct {
+	// if true, trailing sentence-final particles, auxiliary verbs and symbols are skipped when picking the last kana.
+	skipParticles bool
+	// reading of the whole text specified by the client. used for determining head/last if it is plausible for the text.
+	readingHint string
+
+	// number of morae of head/last to return. if 0, morae are not returned.
+	moraCount int
+	// if true, candidates of head/last considering ambiguity of reading are returned.
+	withCandidates bool
+	// if true, custom emoji shortcodes (e.g. ":foo:") declared in customEmojis are read as words instead of being removed.
+	readCustomEmoji bool
+	// shortcodes of custom emoji declared in NIP-30 emoji tags of the event (e.g. "wayo" for ["emoji", "wayo", "<url>"]).
+	// only these shortcodes are read if readCustomEmoji is set, while others are always removed.
+	customEmojis []string
+
+	// options below are only available in /v1/ endpoints. zero values are the legacy behavior.
+
+	// how to treat long vowel marks (ー) at the end of readings.
+	longVowel longVowelPolicy
+	// how to read English words that are not in the dictionary.
+	enFallback enFallbackStrategy
+	// how to treat emoji. if empty, emoji are left to the tokenizer.
+	emoji emojiPolicy
+	// heuristic for reading romaji words. if empty, romaji is not detected.
+	romaji romajiDetection
+	// which normalization stages to apply to the text. if empty, only ones of the legacy endpoint are applied.
+	normalization normalizationLevel
+}
+
+// result of analysis of head/last of reading of the text.
+type headLastResult struct {
+	head rune
+	last rune
+
+	// normalized text and its tokens
+	normalized string
+	tokens     []tokenizer.Token
+	// readings of each token assigned by the reading hint. nil if no hint is given.
+	hintedReadings []string
+	// indices of tokens from which head/last kana are derived
+	headIdx int
+	lastIdx int
+}
+
+func (hl *headLastResult) headToken() tokenizer.Token {
+	return hl.tokens[hl.headIdx]
+}
+
+func (hl *headLastResult) lastToken() tokenizer.Token {
+	return hl.tokens[hl.lastIdx]
+}
+
+func analyzeHeadAndLast(s string, opts analyzeOptions) (*headLastResult, error) {
+	if err := checkReadability(s, opts); err != nil {
+		return nil, err
+	}
+	normalized := normalizeText(s, opts)
+	return analyzeTokens(normalized, tokenize(normalized), opts)
+}
+
+// analyzes head/last of reading from the normalized text and its tokens.
+func analyzeTokens(normalized string, tokens []tokenizer.Token, opts analyzeOptions) (*headLastResult, error) {
+	// if reading hint is given, determine head/last from readings of tokens assigned by the hint
+	var hinted []string
+	if opts.readingHint != "" {
+		var err error
+		if hinted, err = alignReadingHint(tokens, opts.readingHint, opts); err != nil {
+			return nil, err
+		}
+	}
+
+	hl, err := pickHeadAndLast(tokens, hinted, opts)
+	if err != nil {
+		return nil, err
+	}
+	hl.normalized = normalized
+	return hl, nil
+}
+
+// picks tokens from which head/last kana are derived.
+// if hinted is not nil, readings of tokens are taken from it instead of the tokens themselves.
+func pickHeadAndLast(tokens []tokenizer.Token, hinted []string, opts analyzeOptions) (*headLastResult, error) {
+	headOf := func(i int) rune {
+		if hinted != nil {
+			return headKana(hinted[i])
+		}
+		k, _ := headKanaOfToken(tokens[i], opts)
+		return k
+	}
+	lastOf := func(i int) rune {
+		if hinted != nil {
+			return lastKana(hinted[i])
+		}
+		k, _ := lastKanaOfToken(tokens[i], opts)
+		return k
+	}
 
 	var (
 		h = 0
@@ -153,20 +449,59 @@ func effectiveHeadAndLast(s string) (rune, rune, error) {
 	)
 
 	for ; h < len(tokens); h++ {
-		if head = headKanaOfToken(tokens[h]); head != 0 {
+		if head = headOf(h); head != 0 {
 			break
 		}
 	}
-	for ; l >= h; l-- {
-		if last = lastKanaOfToken(tokens[l]); last != 0 {
+	if opts.skipParticles {
+		// walk back over trailing particles, auxiliary verbs and symbols to find the last content word.
+		// if there is no content word, fall back to the literal last token.
+		for ; l >= h; l-- {
+			if isSkippableTrailingToken(tokens[l]) {
+				continue
+			}
+			if last = lastOf(l); last != 0 {
+				break
+			}
+		}
+		if last == 0 {
+			l = len(tokens) - 1
+		}
+	}
+	for ; last == 0 && l >= h; l-- {
+		if last = lastOf(l); last != 0 {
 			break
 		}
 	}
 
 	if head == 0 || last == 0 {
-		return 0, 0, errors.New("effectiveHeadAndLast: something wrong")
-	}
-	return head, last, nil
+		return nil, newUnreadableError(unreadableReasonOfTokens(tokens))
+	}
+	return &headLastResult{
+		head:           head,
+		last:           last,
+		tokens:         tokens,
+		hintedReadings: hinted,
+		headIdx:        h,
+		lastIdx:        l,
+	}, nil
+}
+
+// checks if the token is a function word or a symbol that can be skipped when picking the last kana.
+// targets are sentence-final particles (終助詞), auxiliary verbs (助動詞) and symbols (記号).
+func isSkippableTrailingToken(t tokenizer.Token) bool {
+	pos := t.POS()
+	if len(pos) == 0 {
+		return false
+	}
+	switch pos[0] {
+	case "助動詞", "記号", "補助記号": // 補助記号 is for UniDic
+		return true
+	case "助詞":
+		// IPA dictionary has compound subcategories like "副助詞／並立助詞／終助詞"
+		return len(pos) > 1 && strings.Contains(pos[1], "終助詞")
+	}
+	return false
 }
 
 var hwKana2FwKana = map[rune]rune{
@@ -362,19 +697,49 @@ var (
 // normalize the string for determining reading.
 //
 // normalization proecss includes:
+//   - normalizing Unicode representation of kana (see normalizeUnicode)
 //   - normalizing various space characters to the "normal" space
 //   - removing http/ws URIs, Nostr IDs (`nxxx1...` things, including `nostr:` prefix) and custom emoji shortcodes (e.g. ":foo:")
-//   - replacing numbers (sequences of digits) with their readings
+//     (if readCustomEmoji option is set, shortcodes declared in emoji tags are replaced with their names instead)
+//   - replacing emoji with their short names, or removing them (see replaceEmoji)
+//   - replacing inline ruby notations (e.g. "漢字《かんじ》", "{漢字|かんじ}") with their readings
+//   - replacing dates, clock times and version strings with their readings (see replaceNumericFormats)
+//   - replacing numbers followed by counter words with their readings (see replaceNumbersWithCounter)
+//   - replacing units following numbers with their readings, e.g. "5km" (see symbol dictionary)
+//   - splitting compound alphanumeric words into parts, e.g. "GitHub" -> "Git Hub", "iPhone15" -> "iPhone 15" (see splitEnCompoundWords)
+//   - replacing numbers (sequences of digits, including fullwidth ones and kanji numerals) with their readings
 //   - trimming trailing period
 //   - replacing words in replace dictionary
+//   - replacing English contractions and possessives with their readings, e.g. "they've", "Alice's" (see replaceContractions)
+//   - replacing symbols with their readings, e.g. "℃", "＋" (see symbol dictionary)
 //
 // trimming trailing period is necessary because kagome tokenizer sometimes group "the last character of word and the next period" mistakenly(e.g. "punk." -> ["pun", "k."]).
 // replacing words is necessary because kagome tokenizer tokenizes words that have "'" in wrong way.
-func normalizeText(s string) string {
-	res := regexpSpaces.ReplaceAllString(s, " ")
+func normalizeText(s string, opts analyzeOptions) string {
+	full := opts.normalization == normalizationFull
+
+	res := s
+	if full {
+		res = normalizeUnicode(res)
+	}
+	res = regexpSpaces.ReplaceAllString(res, " ")
 	res = regexpHTTPURI.ReplaceAllString(res, " ")
 	res = regexpNostrID.ReplaceAllString(res, " ")
-	res = regexpCustomEmoji.ReplaceAllString(res, " ")
+	if full {
+		res = replaceInlineRuby(res)
+	}
+	res = replaceEmoji(res, opts.emoji)
+	res = replaceCustomEmoji(res, opts)
+
+	rd, rpd, sd := currentDicts()
+	if full {
+		res = fullwidthDigitsToASCII(res)
+		res = replaceNumericFormats(res)
+		res = replaceNumbersWithCounter(res)
+		res = sd.ReplaceUnits(movePrefixedCurrencySymbols(res))
+		res = splitEnCompoundWords(res, rd)
+		res = replaceJaNumbers(res)
+	}
 	res = regexpNumber.ReplaceAllStringFunc(res, func(s string) string {
 		cut, isNeg := strings.CutPrefix(s, "-")
 		numReading := getNumberReading(strings.NewReplacer(",", "", "_", "").Replace(cut))
@@ -386,32 +751,41 @@ func normalizeText(s string) string {
 	})
 	res = strings.TrimRight(res, ".")
 
-	for re, repl := range replaceDict {
-		res = re.ReplaceAllString(res, repl)
+	// contractions are applied on every endpoint, since they replace entries of the replace dictionary of the legacy endpoint.
+	res = replaceContractions(rpd.Replace(res), rd)
+	if !full {
+		return res
 	}
-	return res
+	// symbols are replaced last, after English words with apostrophes are read by the replace dictionary and contractions.
+	// note that the replace dictionary matches words at word boundaries like `\b`, so words ending with symbols (e.g. "C++") don't match at the end of words.
+	return sd.ReplaceSymbols(res)
 }
 
 // credit to basic idea: https://gist.github.com/ikegami-yukino/2213879
 // only replaces end of readings, which affect shiritori connections.
-var enWordReadingNaturalizations = map[*regexp.Regexp]string{
-	regexp.MustCompile(`([ドト])ゥ$`):       "$1",
-	regexp.MustCompile(`([キシチニヒミリィ])イ$`): "${1}ー",
-	regexp.MustCompile(`ォウ$`):            "ォー",
-	regexp.MustCompile(`ロウ$`):            "ロー",
+// rules are applied in this order.
+var enWordReadingNaturalizations = []struct {
+	re   *regexp.Regexp
+	repl string
+}{
+	{regexp.MustCompile(`([ドト])ゥ$`), "$1"},
+	{regexp.MustCompile(`([キシチニヒミリィ])イ$`), "${1}ー"},
+	{regexp.MustCompile(`ォウ$`), "ォー"},
+	{regexp.MustCompile(`ロウ$`), "ロー"},
 }
 
 func naturalizeEnWordReading(r string) string {
 	res := r
-	for re, repl := range enWordReadingNaturalizations {
-		res = re.ReplaceAllString(res, repl)
+	for _, n := range enWordReadingNaturalizations {
+		res = n.re.ReplaceAllString(res, n.repl)
 	}
 	return res
 }
 
 // pre-condition: word is uppercased
 func getEnWordReading(word string) (string, bool) {
-	if r, ok := readingDict[word]; ok {
+	rd, _, _ := currentDicts()
+	if r, ok := rd[word]; ok {
 		return naturalizeEnWordReading(r), true
 	}
 	return "", false
@@ -421,12 +795,30 @@ var (
 	regexpAllEnAlphabet = regexp.MustCompile(`^[a-zA-Z]+$`)
 	regexpAllHwKana     = regexp.MustCompile(`^[ｦ-ﾟ]+$`)
 	regexpAllFwKana     = regexp.MustCompile(`^[ぁ-ゖァ-ヶ]+$`)
+	// fullwidth kana word which may contain long vowel marks (e.g. ユーアー), which is read as written
+	regexpFwKanaWord = regexp.MustCompile(`^[ぁ-ゖァ-ヶ][ぁ-ゖァ-ヶー]*$`)
 )
 
-func headKanaOfToken(t tokenizer.Token) rune {
+// source from which kana of a token is derived.
+type kanaSource string
+
+const (
+	kanaSourceNone      kanaSource = ""
+	kanaSourceFullwidth kanaSource = "fullwidth"
+	kanaSourceHalfwidth kanaSource = "halfwidth"
+	kanaSourceReading   kanaSource = "reading"
+	kanaSourceEnDict    kanaSource = "enDict"
+	kanaSourceRomaji    kanaSource = "romaji"
+	kanaSourceGuessed   kanaSource = "guessed"
+	kanaSourceAlphabet  kanaSource = "alphabet"
+	kanaSourceSurface   kanaSource = "surface"
+	kanaSourceHint      kanaSource = "hint"
+)
+
+func headKanaOfToken(t tokenizer.Token, opts analyzeOptions) (rune, kanaSource) {
 	// if the token consists of only fullwidth katakana, just get head
 	if regexpAllFwKana.MatchString(t.Surface) {
-		return normalizeSingleKana([]rune(t.Surface)[0])
+		return normalizeSingleKana([]rune(t.Surface)[0]), kanaSourceFullwidth
 	}
 
 	// if the token consists of only halfwidth katakana, get head and convert it to fullwidth
@@ -439,15 +831,15 @@ func headKanaOfToken(t tokenizer.Token) rune {
 			}
 		}
 		if h >= len(rs) {
-			return 0
+			return 0, kanaSourceNone
 		}
-		return normalizeKanaAt(rs, h)
+		return normalizeKanaAt(rs, h), kanaSourceHalfwidth
 	}
 
 	// get head kana from reading of the token
-	if r, ok := t.Reading(); ok {
+	if r, ok := readingOfToken(t); ok {
 		if k := headKana(r); k != 0 {
-			return k
+			return k, kanaSourceReading
 		}
 	}
 
@@ -457,24 +849,40 @@ func headKanaOfToken(t tokenizer.Token) rune {
 		upper := strings.ToUpper(t.Surface)
 		if r, ok := getEnWordReading(upper); ok {
 			if k := headKana(r); k != 0 {
-				return k
+				return k, kanaSourceEnDict
+			}
+		}
+		// then, read it as romaji if it is likely a romaji word
+		if r, ok := romajiReading(t.Surface, opts.romaji); ok {
+			if k := headKana(r); k != 0 {
+				return k, kanaSourceRomaji
+			}
+		}
+		// if reading is not available, guess the pronunciation or use literal reading of first alphabet
+		switch opts.enFallback {
+		case enFallbackNone:
+			return 0, kanaSourceNone
+		case enFallbackGuess:
+			if r, ok := guessEnWordReading(t.Surface); ok {
+				if k := headKana(r); k != 0 {
+					return k, kanaSourceGuessed
+				}
 			}
 		}
-		// if reading is not available, use literal reading of first alphabet
 		if r, ok := enAlphabetReadings[rune(upper[0])]; ok {
 			if k := headKana(r); k != 0 {
-				return k
+				return k, kanaSourceAlphabet
 			}
 		}
-		return 0
+		return 0, kanaSourceNone
 	}
 
 	// get head kana from surface form of the token
 	if k := headKana(t.Surface); k != 0 {
-		return normalizeSingleKana(k)
+		return normalizeSingleKana(k), kanaSourceSurface
 	}
 
-	return 0
+	return 0, kanaSourceNone
 }
 
 func headKana(r string) rune {
@@ -486,16 +894,16 @@ func headKana(r string) rune {
 	return 0
 }
 
-func lastKanaOfToken(t tokenizer.Token) rune {
+func lastKanaOfToken(t tokenizer.Token, opts analyzeOptions) (rune, kanaSource) {
 	// if the token consists of only fullwidth katakana, just get last
 	if regexpAllFwKana.MatchString(t.Surface) {
 		rs := []rune(t.Surface)
-		return normalizeSingleKana(rs[len(rs)-1])
+		return normalizeSingleKana(rs[len(rs)-1]), kanaSourceFullwidth
 	}
 
 	// if the token consists of only halfwidth katakana, get last and convert it to fullwidth
 	if regexpAllHwKana.MatchString(t.Surface) {
-		rs := []rune(t.Surface)
+		rs := []rune(opts.expandLongVowels(t.Surface))
 		l := len(rs) - 1
 		for ; l >= 0; l-- {
 			if isKana(rs[l]) {
@@ -503,15 +911,15 @@ func lastKanaOfToken(t tokenizer.Token) rune {
 			}
 		}
 		if l < 0 {
-			return 0
+			return 0, kanaSourceNone
 		}
-		return normalizeKanaAt(rs, l)
+		return normalizeKanaAt(rs, l), kanaSourceHalfwidth
 	}
 
 	// get last kana from reading of the token
-	if r, ok := t.Reading(); ok {
-		if k := lastKana(r); k != 0 {
-			return k
+	if r, ok := readingOfToken(t); ok {
+		if k := lastKana(opts.expandLongVowels(r)); k != 0 {
+			return k, kanaSourceReading
 		}
 	}
 
@@ -520,24 +928,40 @@ func lastKanaOfToken(t tokenizer.Token) rune {
 		// first, get reading from dictionary and get last kana
 		upper := strings.ToUpper(t.Surface)
 		if r, ok := getEnWordReading(upper); ok {
-			if k := lastKana(r); k != 0 {
-				return k
+			if k := lastKana(opts.expandLongVowels(r)); k != 0 {
+				return k, kanaSourceEnDict
+			}
+		}
+		// then, read it as romaji if it is likely a romaji word
+		if r, ok := romajiReading(t.Surface, opts.romaji); ok {
+			if k := lastKana(opts.expandLongVowels(r)); k != 0 {
+				return k, kanaSourceRomaji
+			}
+		}
+		// if reading is not available, guess the pronunciation or use literal reading of last alphabet
+		switch opts.enFallback {
+		case enFallbackNone:
+			return 0, kanaSourceNone
+		case enFallbackGuess:
+			if r, ok := guessEnWordReading(t.Surface); ok {
+				if k := lastKana(opts.expandLongVowels(r)); k != 0 {
+					return k, kanaSourceGuessed
+				}
 			}
 		}
-		// if reading is not available, use literal reading of last alphabet
 		if r, ok := enAlphabetReadings[rune(upper[len(upper)-1])]; ok {
-			if k := lastKana(r); k != 0 {
-				return k
+			if k := lastKana(opts.expandLongVowels(r)); k != 0 {
+				return k, kanaSourceAlphabet
 			}
 		}
-		return 0
+		return 0, kanaSourceNone
 	}
 
 	// get last kana from surface form of the token
-	if k := lastKana(t.Surface); k != 0 {
-		return normalizeSingleKana(k)
+	if k := lastKana(opts.expandLongVowels(t.Surface)); k != 0 {
+		return normalizeSingleKana(k), kanaSourceSurface
 	}
-	return 0
+	return 0, kanaSourceNone
 }
 
 func lastKana(r string) rune {
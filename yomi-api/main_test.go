@@ -22,10 +22,15 @@ func TestNormalizeText(t *testing.T) {
 		{in: "I'd like to", want: "アイド like to"},
 		{in: "-1,234.56", want: "マイナスセンニヒャクサンジュウヨンテンゴロク"},
 		{in: "Japan confirmed punk.", want: "Japan confirmed punk"},
+		// stages added in /v1/ are not applied by default
+		{in: "5km", want: "ゴkm"},
+		{in: "１２：３０", want: "１２：３０"},
+		{in: "1＋1＝2", want: "イチ＋イチ＝ニ"},
+		{in: "{漢字|kanji}", want: "{漢字|kanji}"},
 	}
 
 	for _, tt := range tests {
-		if got := normalizeText(tt.in); got != tt.want {
+		if got := normalizeText(tt.in, analyzeOptions{}); got != tt.want {
 			t.Errorf("normalizeText(%q) = %q; want %q", tt.in, got, tt.want)
 		}
 	}
@@ -141,3 +146,63 @@ func TestEffectiveHeadAndList(t *testing.T) {
 		}
 	}
 }
+
+func TestAnalyzeHeadAndLast_POS(t *testing.T) {
+	if err := initialize(); err != nil {
+		log.Fatal(err)
+	}
+
+	tests := []struct {
+		in      string
+		headPOS string
+		lastPOS string
+	}{
+		{in: "りんご", headPOS: "名詞", lastPOS: "名詞"},
+		{in: "走る", headPOS: "動詞", lastPOS: "動詞"},
+		{in: "美しい花", headPOS: "形容詞", lastPOS: "名詞"},
+		{in: "寿司を食べた！", headPOS: "名詞", lastPOS: "助動詞"},
+	}
+
+	for _, tt := range tests {
+		hl, err := analyzeHeadAndLast(tt.in, analyzeOptions{})
+		if err != nil {
+			t.Errorf("analyzeHeadAndLast(%q) got unexpected error: %v", tt.in, err)
+			continue
+		}
+		if got := hl.headToken().POS()[0]; got != tt.headPOS {
+			t.Errorf("analyzeHeadAndLast(%q).headToken POS = %q; want %q", tt.in, got, tt.headPOS)
+		}
+		if got := hl.lastToken().POS()[0]; got != tt.lastPOS {
+			t.Errorf("analyzeHeadAndLast(%q).lastToken POS = %q; want %q", tt.in, got, tt.lastPOS)
+		}
+	}
+}
+
+func TestAnalyzeHeadAndLast_skipParticles(t *testing.T) {
+	if err := initialize(); err != nil {
+		log.Fatal(err)
+	}
+
+	tests := []struct {
+		in          string
+		last        rune
+		lastSurface string
+	}{
+		{in: "今日は寿司だよね", last: 'シ', lastSurface: "寿司"},
+		{in: "りんごを食べたよ！", last: 'ベ', lastSurface: "食べ"},
+		{in: "ラーメンです。", last: 'ン', lastSurface: "ラーメン"},
+		{in: "ねこ", last: 'コ', lastSurface: "ねこ"},
+		{in: "よね", last: 'ネ', lastSurface: "ね"},
+	}
+
+	for _, tt := range tests {
+		hl, err := analyzeHeadAndLast(tt.in, analyzeOptions{skipParticles: true})
+		if err != nil {
+			t.Errorf("analyzeHeadAndLast(%q) got unexpected error: %v", tt.in, err)
+			continue
+		}
+		if hl.last != tt.last || hl.lastToken().Surface != tt.lastSurface {
+			t.Errorf("analyzeHeadAndLast(%q) = %q (from %q); want %q (from %q)", tt.in, hl.last, hl.lastToken().Surface, tt.last, tt.lastSurface)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/ikawaha/kagome/v2/tokenizer"
+)
+
+// small kana that form a single mora together with the preceding kana (拗音).
+var smallKanaForYoon = map[rune]struct{}{
+	'ァ': {},
+	'ィ': {},
+	'ゥ': {},
+	'ェ': {},
+	'ォ': {},
+	'ャ': {},
+	'ュ': {},
+	'ョ': {},
+	'ヮ': {},
+}
+
+// split reading in fullwidth katakana into morae for multi-mora shiritori.
+// kana that can't start a word are grouped with the adjacent kana, so that leading/trailing morae are always playable:
+//   - small kana that form 拗音, long vowel marks (ー) and ン are grouped with the preceding kana
+//     (e.g. "キャク" -> ["キャ", "ク"], "コーヒー" -> ["コー", "ヒー"], "カンジ" -> ["カン", "ジ"])
+//   - ッ is grouped with the following kana (e.g. "サッカー" -> ["サ", "ッカー"]), or with the preceding kana if it is at the end
+//
+// if there is no kana to be grouped with, they form morae by themselves, except that leading long vowel marks are ignored.
+// other non-kana characters are ignored.
+func splitMorae(r string) []string {
+	morae := make([]string, 0, len(r)/3)
+	sokuon := ""
+	for _, c := range r {
+		if !isFullwidthKatakana(c) && c != 'ー' {
+			continue
+		}
+		_, yoon := smallKanaForYoon[c]
+		switch {
+		case c == 'ッ' || sokuon != "" && (yoon || c == 'ー' || c == 'ン'):
+			sokuon += string(c)
+		case sokuon != "":
+			morae = append(morae, sokuon+string(c))
+			sokuon = ""
+		case (yoon || c == 'ー' || c == 'ン') && len(morae) > 0:
+			morae[len(morae)-1] += string(c)
+		case c == 'ー':
+			continue
+		default:
+			morae = append(morae, string(c))
+		}
+	}
+	if sokuon != "" {
+		if len(morae) > 0 {
+			morae[len(morae)-1] += sokuon
+		} else {
+			morae = append(morae, sokuon)
+		}
+	}
+	return morae
+}
+
+// returns the whole reading of the token, normalized to fullwidth katakana, along with its source.
+// the source of reading is chosen in the same manner as headKanaOfToken/lastKanaOfToken.
+func kanaReadingOfToken(t tokenizer.Token, opts analyzeOptions) (string, kanaSource) {
+	// if the token consists of only fullwidth katakana, just normalize it
+	if regexpAllFwKana.MatchString(t.Surface) {
+		return normalizeKanaString(t.Surface), kanaSourceFullwidth
+	}
+
+	// if the token consists of only halfwidth katakana, convert it to fullwidth
+	if regexpAllHwKana.MatchString(t.Surface) {
+		return normalizeKanaString(opts.expandLongVowels(t.Surface)), kanaSourceHalfwidth
+	}
+
+	// use reading of the token
+	if r, ok := readingOfToken(t); ok {
+		if k := normalizeKanaString(opts.expandLongVowels(r)); k != "" {
+			return k, kanaSourceReading
+		}
+	}
+
+	// if the token is likely an English word...
+	if regexpAllEnAlphabet.MatchString(t.Surface) {
+		// first, get reading from dictionary
+		upper := strings.ToUpper(t.Surface)
+		if r, ok := getEnWordReading(upper); ok {
+			if k := normalizeKanaString(opts.expandLongVowels(r)); k != "" {
+				return k, kanaSourceEnDict
+			}
+		}
+		// then, read it as romaji if it is likely a romaji word
+		if r, ok := romajiReading(t.Surface, opts.romaji); ok {
+			if k := normalizeKanaString(opts.expandLongVowels(r)); k != "" {
+				return k, kanaSourceRomaji
+			}
+		}
+		// if reading is not available, guess the pronunciation or use literal reading of each alphabet
+		switch opts.enFallback {
+		case enFallbackNone:
+			return "", kanaSourceNone
+		case enFallbackGuess:
+			if r, ok := guessEnWordReading(t.Surface); ok {
+				if k := normalizeKanaString(opts.expandLongVowels(r)); k != "" {
+					return k, kanaSourceGuessed
+				}
+			}
+		}
+		return normalizeKanaString(opts.expandLongVowels(spellEnWord(upper))), kanaSourceAlphabet
+	}
+
+	// use kana in surface form of the token
+	if k := normalizeKanaString(opts.expandLongVowels(t.Surface)); k != "" {
+		return k, kanaSourceSurface
+	}
+	return "", kanaSourceNone
+}
+
+// returns literal reading of each alphabet in the word.
+// pre-condition: word is uppercased
+func spellEnWord(word string) string {
+	var b strings.Builder
+	for _, c := range word {
+		b.WriteString(enAlphabetReadings[c])
+	}
+	return b.String()
+}
+
+// extracts kana from the string and normalizes them to fullwidth katakana.
+// long vowel marks following kana are kept as "ー" (they are part of morae, see splitMorae). other characters are dropped.
+func normalizeKanaString(s string) string {
+	rs := []rune(s)
+
+	var b strings.Builder
+	for i := range rs {
+		if (rs[i] == 'ー' || rs[i] == 'ｰ') && b.Len() > 0 {
+			b.WriteRune('ー')
+			continue
+		}
+		if k := normalizeKanaAt(rs, i); k != 0 {
+			b.WriteRune(k)
+		}
+	}
+	return b.String()
+}
+
+// returns leading and trailing n morae of reading of the text. resulting morae will be normalized to fullwidth katakana.
+// if the whole reading is shorter than n morae, all of morae will be returned.
+func effectiveHeadAndLastMorae(s string, n int) ([]string, []string, error) {
+	hl, err := analyzeHeadAndLast(s, analyzeOptions{})
+	if err != nil {
+		return nil, nil, err
+	}
+	head, last := hl.morae(n, analyzeOptions{})
+	return head, last, nil
+}
+
+// returns leading n morae of reading starting from the head token, and trailing n morae of reading ending with the last token.
+// readings of tokens are joined before splitting, since kana at the boundaries of tokens (e.g. ッ in "混ざった") may be grouped with ones of adjacent tokens.
+func (hl *headLastResult) morae(n int, opts analyzeOptions) ([]string, []string) {
+	readings := make([]string, len(hl.tokens))
+	for i, t := range hl.tokens {
+		if hl.hintedReadings != nil {
+			readings[i] = hl.hintedReadings[i]
+		} else {
+			readings[i], _ = kanaReadingOfToken(t, opts)
+		}
+	}
+
+	head := splitMorae(strings.Join(readings[hl.headIdx:], ""))
+	last := splitMorae(strings.Join(readings[:hl.lastIdx+1], ""))
+	return head[:min(len(head), n)], last[max(len(last)-n, 0):]
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"log"
+	"slices"
+	"testing"
+)
+
+func TestSplitMorae(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{in: "アイウエオ", want: []string{"ア", "イ", "ウ", "エ", "オ"}},
+		{in: "キャク", want: []string{"キャ", "ク"}},
+		{in: "ヴァイオリン", want: []string{"ヴァ", "イ", "オ", "リン"}},
+		// long vowel marks and ン are grouped with the preceding kana, and ッ with the following kana
+		{in: "ラーメン", want: []string{"ラー", "メン"}},
+		{in: "コーヒー", want: []string{"コー", "ヒー"}},
+		{in: "ガッコウ", want: []string{"ガ", "ッコ", "ウ"}},
+		{in: "サッカー", want: []string{"サ", "ッカー"}},
+		{in: "ヴァッ", want: []string{"ヴァッ"}},
+		// if there is no kana to be grouped with, they form morae by themselves (except leading long vowel marks)
+		{in: "ョット", want: []string{"ョ", "ット"}},
+		{in: "ンジャメナ", want: []string{"ン", "ジャ", "メ", "ナ"}},
+		{in: "ッ", want: []string{"ッ"}},
+		{in: "ーア", want: []string{"ア"}},
+		{in: "", want: []string{}},
+	}
+
+	for _, tt := range tests {
+		if got := splitMorae(tt.in); !slices.Equal(got, tt.want) {
+			t.Errorf("splitMorae(%q) = %q; want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestEffectiveHeadAndLastMorae(t *testing.T) {
+	if err := initialize(); err != nil {
+		log.Fatal(err)
+	}
+
+	tests := []struct {
+		in      string
+		n       int
+		wantErr bool
+		head    []string
+		last    []string
+	}{
+		{in: "あいうえお", n: 2, head: []string{"ア", "イ"}, last: []string{"エ", "オ"}},
+		{in: "あいうえお", n: 1, head: []string{"ア"}, last: []string{"オ"}},
+		{in: "しゃしん", n: 2, head: []string{"シャ", "シン"}, last: []string{"シャ", "シン"}},
+		{in: "ｳﾞｧｯ", n: 2, head: []string{"ヴァッ"}, last: []string{"ヴァッ"}},
+		{in: "漢字", n: 2, head: []string{"カン", "ジ"}, last: []string{"カン", "ジ"}},
+		// ッ at the end of a token is grouped with the kana of the next token
+		{in: "カナと漢字が混ざった文", n: 3, head: []string{"カ", "ナ", "ト"}, last: []string{"ザ", "ッタ", "ブン"}},
+		{in: "ostrich", n: 2, head: []string{"オー", "ス"}, last: []string{"リ", "ッチ"}},
+		{in: "コーヒー", n: 2, head: []string{"コー", "ヒー"}, last: []string{"コー", "ヒー"}},
+		{in: "サッカー", n: 2, head: []string{"サ", "ッカー"}, last: []string{"サ", "ッカー"}},
+		{in: "nostr", n: 2, head: []string{"ノ", "ス"}, last: []string{"ス", "ター"}},
+		{in: "あ", n: 2, head: []string{"ア"}, last: []string{"ア"}},
+		{in: "！？", n: 2, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		head, last, err := effectiveHeadAndLastMorae(tt.in, tt.n)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("effectiveHeadAndLastMorae(%q, %d) = %q, %q; want error", tt.in, tt.n, head, last)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("effectiveHeadAndLastMorae(%q, %d) = %q, %q, %v; want no error", tt.in, tt.n, head, last, err)
+		}
+		if !slices.Equal(head, tt.head) || !slices.Equal(last, tt.last) {
+			t.Errorf("effectiveHeadAndLastMorae(%q, %d) = %q, %q; want %q, %q", tt.in, tt.n, head, last, tt.head, tt.last)
+		}
+	}
+}
@@ -1,7 +1,11 @@
 package main
 
 import (
+	"regexp"
+	"strconv"
 	"strings"
+	"unicode"
+	"unicode/utf8"
 )
 
 var basicDigitReading = map[rune]string{
@@ -145,3 +149,245 @@ func getNumberReading(strNum string) string {
 	}
 	return res
 }
+
+var kanjiDigits = map[rune]int64{
+	'〇': 0,
+	'零': 0,
+	'一': 1,
+	'二': 2,
+	'三': 3,
+	'四': 4,
+	'五': 5,
+	'六': 6,
+	'七': 7,
+	'八': 8,
+	'九': 9,
+}
+
+var kanjiSmallUnits = map[rune]int64{
+	'十': 10,
+	'百': 100,
+	'千': 1000,
+}
+
+var kanjiBigUnits = map[rune]int64{
+	'万': 1_0000,
+	'億': 1_0000_0000,
+	'兆': 1_0000_0000_0000,
+}
+
+var kanjiBigUnitReadings = map[rune]string{
+	'万': "マン",
+	'億': "オク",
+	'兆': "チョウ",
+}
+
+// numbers written in kanji numerals, possibly mixed with arabic numerals (e.g. 二十三, 三千五百, 3万, 1.5億)
+var regexpJaNumber = regexp.MustCompile(`[0-9,_.〇零一二三四五六七八九十百千万億兆]*[〇零一二三四五六七八九十百千万億兆][0-9,_.〇零一二三四五六七八九十百千万億兆]*`)
+
+// converts fullwidth digits to ASCII ones.
+func fullwidthDigitsToASCII(s string) string {
+	return strings.Map(func(r rune) rune {
+		if '０' <= r && r <= '９' {
+			return r - '０' + '0'
+		}
+		return r
+	}, s)
+}
+
+// replaces numbers written in kanji numerals (possibly mixed with arabic numerals) with their readings.
+//
+// to avoid breaking words that contain kanji numerals (e.g. 一人, 八百屋, 万一), numbers consisting of only kanji are replaced only if:
+//   - they consist of 2 or more kanji numerals, or they are a single zero (〇, 零) which isn't read well by the tokenizer, and
+//   - they are not (a part of) words in the dictionary, except for numerals and proper nouns
+//     (ipa-neologd has lots of proper nouns like titles of works that consist of numerals)
+func replaceJaNumbers(s string) string {
+	idxs := regexpJaNumber.FindAllStringIndex(s, -1)
+	if idxs == nil {
+		return s
+	}
+
+	var b strings.Builder
+	prev := 0
+	for _, idx := range idxs {
+		start, end := idx[0], idx[1]
+		// separators at the ends are not a part of the number
+		for start < end && strings.IndexByte(",_.", s[start]) >= 0 {
+			start++
+		}
+		for end > start && strings.IndexByte(",_.", s[end-1]) >= 0 {
+			end--
+		}
+		num := s[start:end]
+
+		b.WriteString(s[prev:start])
+		if r, ok := jaNumberReading(num); ok && (strings.ContainsAny(num, "0123456789") ||
+			utf8.RuneCountInString(num) >= 2 && !isInNonNumeralWord(s[start:], len(num)) ||
+			(num == "〇" || num == "零") && !isZeroNotNumeral(s, start, end)) {
+			b.WriteString(r)
+		} else {
+			b.WriteString(num)
+		}
+		prev = end
+	}
+	b.WriteString(s[prev:])
+	return b.String()
+}
+
+// checks if the single zero (s[start:end]) is not used as a numeral: a part of a longer word in the dictionary (e.g. 零下),
+// or a mark next to other symbols (e.g. 〇×).
+// the zero itself is in the dictionary as a symbol, so it is not counted as a word.
+func isZeroNotNumeral(s string, start, end int) bool {
+	if r, _ := utf8.DecodeLastRuneInString(s[:start]); start > 0 && unicode.IsSymbol(r) {
+		return true
+	}
+	if r, _ := utf8.DecodeRuneInString(s[end:]); end < len(s) && unicode.IsSymbol(r) {
+		return true
+	}
+	return isKanjiNumberInWord(s, start, end+1)
+}
+
+// checks if the first n bytes of s is (a part of) a word in the dictionary that is neither a numeral nor a proper noun.
+func isInNonNumeralWord(s string, n int) bool {
+	lens, ids := kagomeDict.Index.CommonPrefixSearch(s)
+	for i, l := range lens {
+		if l < n {
+			continue
+		}
+		for _, id := range ids[i] {
+			pos := kagomeDict.POSTable.POSs[id]
+			if len(pos) < 2 {
+				return true
+			}
+			// 名詞,数 (IPA) or 名詞,数詞 (UniDic), or 名詞,固有名詞
+			if sub := kagomeDict.POSTable.NameList[pos[1]]; !strings.Contains(sub, "数") && sub != "固有名詞" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// returns the reading of the number written in kanji numerals (possibly mixed with arabic numerals).
+// if the number is malformed, returns false.
+func jaNumberReading(num string) (string, bool) {
+	rs := []rune(strings.NewReplacer(",", "", "_", "").Replace(num))
+
+	// decimal number followed by a big unit (e.g. 1.5億) is read as is (イッテンゴオク)
+	if l := len(rs) - 1; l > 0 && strings.ContainsRune(string(rs), '.') {
+		u, ok := kanjiBigUnitReadings[rs[l]]
+		if !ok || !isDecimalNumber(string(rs[:l])) {
+			return "", false
+		}
+		return getNumberReading(string(rs[:l])) + u, true
+	}
+
+	var (
+		total   int64
+		section []rune
+		lastBig int64 = 1_0000_0000_0000_0000
+	)
+	for _, r := range rs {
+		u, ok := kanjiBigUnits[r]
+		if !ok {
+			section = append(section, r)
+			continue
+		}
+		v, ok := smallJaNumber(section)
+		if !ok || u >= lastBig || v == 0 {
+			return "", false
+		}
+		total += v * u
+		lastBig = u
+		section = section[:0]
+	}
+	if len(section) > 0 {
+		v, ok := smallJaNumber(section)
+		if !ok {
+			return "", false
+		}
+		total += v
+	}
+	if total >= 1_0000_0000_0000_0000 || total < 0 {
+		return "", false
+	}
+	return getNumberReading(strconv.FormatInt(total, 10)), true
+}
+
+func isDecimalNumber(s string) bool {
+	intPart, decPart, found := strings.Cut(s, ".")
+	if !found || intPart == "" || decPart == "" {
+		return false
+	}
+	for _, r := range intPart + decPart {
+		if r < '0' || '9' < r {
+			return false
+		}
+	}
+	return true
+}
+
+// parses a number less than 10000 (or just a sequence of digits) written in kanji and/or arabic numerals.
+// e.g. 二十三, 三千五百, 5千, 二〇二四, 2024
+func smallJaNumber(rs []rune) (int64, bool) {
+	if len(rs) == 0 {
+		return 0, false
+	}
+
+	// sequence of digits (e.g. 2024, 二〇二四)
+	isDigits := true
+	var digits int64
+	for _, r := range rs {
+		if d, ok := kanjiDigits[r]; ok {
+			digits = digits*10 + d
+		} else if '0' <= r && r <= '9' {
+			digits = digits*10 + int64(r-'0')
+		} else {
+			isDigits = false
+			break
+		}
+		if digits >= 1_0000_0000_0000_0000 {
+			return 0, false
+		}
+	}
+	if isDigits {
+		return digits, true
+	}
+
+	// with small units (e.g. 二十三, 三千五百, 5千)
+	var (
+		total    int64
+		cur      int64 = -1
+		lastUnit int64 = 10000
+	)
+	for _, r := range rs {
+		if d, ok := kanjiDigits[r]; ok {
+			if cur >= 0 {
+				return 0, false
+			}
+			cur = d
+			continue
+		}
+		if '0' <= r && r <= '9' {
+			if cur >= 0 {
+				return 0, false
+			}
+			cur = int64(r - '0')
+			continue
+		}
+		u, ok := kanjiSmallUnits[r]
+		if !ok || u >= lastUnit || cur == 0 {
+			return 0, false
+		}
+		if cur < 0 {
+			cur = 1
+		}
+		total += cur * u
+		cur = -1
+		lastUnit = u
+	}
+	if cur >= 0 {
+		total += cur
+	}
+	return total, true
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"log"
 	"strings"
 	"testing"
 )
@@ -62,3 +63,107 @@ func TestGetNumberReading(t *testing.T) {
 		}
 	}
 }
+
+func TestJaNumberReading(t *testing.T) {
+	tests := []struct {
+		in     string
+		want   string
+		wantOk bool
+	}{
+		{in: "二十三", want: "ニジュウサン", wantOk: true},
+		{in: "三千五百", want: "サンゼンゴヒャク", wantOk: true},
+		{in: "六百", want: "ロッピャク", wantOk: true},
+		{in: "八千八百", want: "ハッセンハッピャク", wantOk: true},
+		{in: "十", want: "ジュウ", wantOk: true},
+		{in: "〇", want: "ゼロ", wantOk: true},
+		{in: "二〇二四", want: "ニセンニジュウヨン", wantOk: true},
+		{in: "3万", want: "サンマン", wantOk: true},
+		{in: "3万5千", want: "サンマンゴセン", wantOk: true},
+		{in: "1,000万", want: "センマン", wantOk: true},
+		{in: "1億2345万6789", want: "イチオクニセンサンビャクヨンジュウゴマンロクセンナナヒャクハチジュウキュウ", wantOk: true},
+		{in: "一兆", want: "イッチョウ", wantOk: true},
+		{in: "十兆", want: "ジッチョウ", wantOk: true},
+		{in: "1.5億", want: "イッテンゴオク", wantOk: true},
+		{in: "0.5万", want: "レイテンゴマン", wantOk: true},
+		{in: "二三十", wantOk: false},
+		{in: "十百", wantOk: false},
+		{in: "万億", wantOk: false},
+		{in: "万", wantOk: false},
+		{in: "1万2億", wantOk: false},
+		{in: "1.5億3千万", wantOk: false},
+		{in: "1.5千", wantOk: false},
+		{in: "1万兆", wantOk: false},
+	}
+
+	for _, tt := range tests {
+		got, ok := jaNumberReading(tt.in)
+		if ok != tt.wantOk || got != tt.want {
+			t.Errorf("jaNumberReading(%s) = %s, %v, want %s, %v", tt.in, got, ok, tt.want, tt.wantOk)
+		}
+	}
+}
+
+func TestReplaceJaNumbers(t *testing.T) {
+	if err := initialize(); err != nil {
+		log.Fatal(err)
+	}
+
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "二十三", want: "ニジュウサン"},
+		{in: "三千五百円", want: "サンゼンゴヒャク円"},
+		{in: "3万人", want: "サンマン人"},
+		{in: "約1.5億円", want: "約イッテンゴオク円"},
+		{in: "1,000万、二千", want: "センマン、ニセン"},
+		{in: "二〇二四年", want: "ニセンニジュウヨン年"},
+		// single kanji numerals and words containing them are left to the tokenizer
+		{in: "一人", want: "一人"},
+		{in: "千葉", want: "千葉"},
+		{in: "八百屋", want: "八百屋"},
+		{in: "万一", want: "万一"},
+		{in: "〇×", want: "〇×"},
+		{in: "〇", want: "ゼロ"},
+		{in: "零", want: "ゼロ"},
+		{in: "得点は〇", want: "得点はゼロ"},
+		{in: "零下", want: "零下"},
+	}
+
+	for _, tt := range tests {
+		if got := replaceJaNumbers(tt.in); got != tt.want {
+			t.Errorf("replaceJaNumbers(%s) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestEffectiveHeadAndLast_numbers(t *testing.T) {
+	if err := initialize(); err != nil {
+		log.Fatal(err)
+	}
+
+	tests := []struct {
+		in   string
+		head rune
+		last rune
+	}{
+		{in: "１２３", head: 'ヒ', last: 'ン'},
+		{in: "二十三", head: 'ニ', last: 'ン'},
+		{in: "三千", head: 'サ', last: 'ン'},
+		{in: "六百", head: 'ロ', last: 'ク'},
+		{in: "3万", head: 'サ', last: 'ン'},
+		{in: "1.5億", head: 'イ', last: 'ク'},
+		{in: "〇", head: 'ゼ', last: 'ロ'},
+	}
+
+	for _, tt := range tests {
+		hl, err := analyzeHeadAndLast(tt.in, analyzeOptions{normalization: normalizationFull})
+		if err != nil {
+			t.Errorf("analyzeHeadAndLast(%s) returned error: %v", tt.in, err)
+			continue
+		}
+		if hl.head != tt.head || hl.last != tt.last {
+			t.Errorf("analyzeHeadAndLast(%s) = %c, %c, want %c, %c", tt.in, hl.head, hl.last, tt.head, tt.last)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	// 2024/01/01, 2024-1-1
+	regexpDate = regexp.MustCompile(`\b(\d{4})[/／-](\d{1,2})[/／-](\d{1,2})\b`)
+	// 1月1日, 2024年1月1日
+	regexpJaDate = regexp.MustCompile(`(?:(\d{1,4})年)?(\d{1,2})月(\d{1,2})日`)
+	// 12:30, 12:30:15
+	regexpClockTime = regexp.MustCompile(`\b(\d{1,2})[:：](\d{2})(?:[:：](\d{2}))?\b`)
+	// v2.10.3, 1.2.3
+	regexpVersion = regexp.MustCompile(`\b(?:([vV])(\d+(?:\.\d+)+)|(\d+(?:\.\d+){2,}))\b`)
+)
+
+// replaces dates, clock times and version strings with their readings.
+// they must be handled before other numbers, because they are broken up into separate numbers otherwise.
+func replaceNumericFormats(s string) string {
+	s = replaceSubmatchFunc(regexpDate, s, func(m []string) (string, bool) {
+		return dateReading(m[1], m[2], m[3])
+	})
+	s = replaceSubmatchFunc(regexpJaDate, s, func(m []string) (string, bool) {
+		return dateReading(m[1], m[2], m[3])
+	})
+	s = replaceSubmatchFunc(regexpClockTime, s, func(m []string) (string, bool) {
+		return clockTimeReading(m[1], m[2], m[3])
+	})
+	s = replaceSubmatchFunc(regexpVersion, s, func(m []string) (string, bool) {
+		if m[1] != "" {
+			return "バージョン" + dottedNumberReading(m[2]), true
+		}
+		return dottedNumberReading(m[3]), true
+	})
+	return s
+}
+
+// replaces all matches of re with the result of repl, which receives submatches.
+// if repl returns false, the match is left as is.
+func replaceSubmatchFunc(re *regexp.Regexp, s string, repl func([]string) (string, bool)) string {
+	return re.ReplaceAllStringFunc(s, func(match string) string {
+		if r, ok := repl(re.FindStringSubmatch(match)); ok {
+			return r
+		}
+		return match
+	})
+}
+
+// returns the reading of the date. year can be empty.
+// if the date is invalid, returns false.
+func dateReading(year, month, day string) (string, bool) {
+	m, _ := strconv.Atoi(month)
+	d, _ := strconv.Atoi(day)
+	if m < 1 || 12 < m || d < 1 || 31 < d {
+		return "", false
+	}
+
+	res := ""
+	if year != "" {
+		y, _ := numberWithCounterReading(year, "年")
+		res += y
+	}
+	mr, _ := numberWithCounterReading(strconv.Itoa(m), "月")
+	res += mr
+	// the first day of a month is ツイタチ, while "1日" alone is read as イチニチ (a day)
+	if d == 1 {
+		return res + "ツイタチ", true
+	}
+	dr, _ := numberWithCounterReading(strconv.Itoa(d), "日")
+	return res + dr, true
+}
+
+// returns the reading of the clock time. second can be empty.
+// zero minutes and seconds are not read (e.g. 12:00 -> ジュウニジ).
+// if the time is invalid, returns false.
+func clockTimeReading(hour, minute, second string) (string, bool) {
+	h, _ := strconv.Atoi(hour)
+	m, _ := strconv.Atoi(minute)
+	sec, _ := strconv.Atoi(second)
+	// hours past midnight are sometimes written like 25:00
+	if 29 < h || 59 < m || 59 < sec {
+		return "", false
+	}
+
+	res, _ := numberWithCounterReading(strconv.Itoa(h), "時")
+	if m != 0 {
+		mr, _ := numberWithCounterReading(strconv.Itoa(m), "分")
+		res += mr
+	}
+	if sec != 0 {
+		sr, _ := numberWithCounterReading(strconv.Itoa(sec), "秒")
+		res += sr
+	}
+	return res, true
+}
+
+// returns the reading of numbers separated by dots, such as version numbers and IP addresses.
+// e.g. 2.10.3 -> ニテンジッテンサン
+func dottedNumberReading(s string) string {
+	parts := strings.Split(s, ".")
+	for i, p := range parts {
+		parts[i] = getNumberReading(p)
+		if i < len(parts)-1 {
+			parts[i] = applyNasalSoundChange(parts[i])
+		}
+	}
+	return strings.Join(parts, "テン")
+}
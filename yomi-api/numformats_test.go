@@ -0,0 +1,65 @@
+package main
+
+import (
+	"log"
+	"testing"
+)
+
+func TestReplaceNumericFormats(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "2024/01/01", want: "ニセンニジュウヨネンイチガツツイタチ"},
+		{in: "2024-4-20", want: "ニセンニジュウヨネンシガツハツカ"},
+		{in: "1月1日", want: "イチガツツイタチ"},
+		{in: "9月14日", want: "クガツジュウヨッカ"},
+		{in: "2025年7月7日", want: "ニセンニジュウゴネンシチガツナノカ"},
+		{in: "12:30", want: "ジュウニジサンジュップン"},
+		{in: "4:00", want: "ヨジ"},
+		{in: "9:05:08", want: "クジゴフンハチビョウ"},
+		{in: "25:00", want: "ニジュウゴジ"},
+		{in: "0:30", want: "レイジサンジュップン"},
+		{in: "00:00", want: "レイジ"},
+		{in: "v2.10.3", want: "バージョンニテンジッテンサン"},
+		{in: "1.2.3", want: "イッテンニテンサン"},
+		// invalid ones are left as is
+		{in: "2024/13/01", want: "2024/13/01"},
+		{in: "13月1日", want: "13月1日"},
+		{in: "12:60", want: "12:60"},
+		{in: "1.5", want: "1.5"},
+		{in: "V8", want: "V8"},
+	}
+
+	for _, tt := range tests {
+		if got := replaceNumericFormats(tt.in); got != tt.want {
+			t.Errorf("replaceNumericFormats(%s) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeText_numericFormats(t *testing.T) {
+	if err := initialize(); err != nil {
+		log.Fatal(err)
+	}
+
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "10%", want: "ジュッパーセント"},
+		{in: "100％", want: "ヒャッパーセント"},
+		{in: "6%", want: "ロクパーセント"},
+		{in: "8%", want: "ハッパーセント"},
+		{in: "３時間", want: "サンジカン"},
+		{in: "4日間", want: "ヨッカカン"},
+		{in: "6ヶ月", want: "ロッカゲツ"},
+		{in: "１２：３０", want: "ジュウニジサンジュップン"},
+	}
+
+	for _, tt := range tests {
+		if got := normalizeText(tt.in, analyzeOptions{normalization: normalizationFull}); got != tt.want {
+			t.Errorf("normalizeText(%s) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
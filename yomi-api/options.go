@@ -0,0 +1,160 @@
+package main
+
+import (
+	"errors"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// policy of treating long vowel marks (ー) at the end of readings.
+type longVowelPolicy string
+
+const (
+	// long vowel marks are ignored, so the kana before them is the last (e.g. コーヒー -> ヒ). this is the legacy behavior.
+	longVowelIgnore longVowelPolicy = "ignore"
+	// long vowel marks are read as the vowel of the preceding kana (e.g. コーヒー -> イ).
+	longVowelVowel longVowelPolicy = "vowel"
+)
+
+// strategy of reading English words that are not in the dictionary.
+type enFallbackStrategy string
+
+const (
+	// read each alphabet literally (e.g. nostr -> エヌオーエスティーアール). this is the legacy behavior.
+	enFallbackSpell enFallbackStrategy = "spell"
+	// don't read such words at all.
+	enFallbackNone enFallbackStrategy = "none"
+	// guess the pronunciation by spelling rules (e.g. crypto -> クリプト).
+	// words that can't be pronounced (e.g. ones without vowels, acronyms in upper case) are read literally, as with "spell".
+	enFallbackGuess enFallbackStrategy = "guess"
+)
+
+// set of normalization stages applied to the text.
+type normalizationLevel string
+
+const (
+	// only stages of the legacy endpoint are applied (spaces, URIs, mentions, emoji, plain numbers and the replace dictionary).
+	normalizationLegacy normalizationLevel = "legacy"
+	// in addition, Unicode normalization, inline ruby, numeric formats, counters, units, symbols,
+	// compound English words and kanji numerals are read.
+	normalizationFull normalizationLevel = "full"
+)
+
+// parses options for analysis from query parameters of /v1/ endpoints.
+// in addition to ones of the legacy endpoint, following options are available:
+//   - longVowel: "ignore" (default) or "vowel"
+//   - enFallback: "spell" (default), "guess" or "none"
+//   - emoji: "read" (default) or "ignore"
+//   - romaji: "off", "strict" or "loose" (default is configured by ROMAJI_DETECTION environment variable)
+//   - normalize: "full" (default) or "legacy"
+func parseV1AnalyzeOptions(q url.Values) (analyzeOptions, error) {
+	opts, err := parseAnalyzeOptions(q)
+	if err != nil {
+		return opts, err
+	}
+
+	switch p := longVowelPolicy(q.Get("longVowel")); p {
+	case "", longVowelIgnore:
+	case longVowelVowel:
+		opts.longVowel = p
+	default:
+		return opts, errors.New("invalid longVowel")
+	}
+	switch s := enFallbackStrategy(q.Get("enFallback")); s {
+	case "", enFallbackSpell:
+	case enFallbackNone, enFallbackGuess:
+		opts.enFallback = s
+	default:
+		return opts, errors.New("invalid enFallback")
+	}
+	switch p := emojiPolicy(q.Get("emoji")); p {
+	case "", emojiRead:
+		opts.emoji = emojiRead
+	case emojiIgnore:
+		opts.emoji = p
+	default:
+		return opts, errors.New("invalid emoji")
+	}
+	opts.romaji = romajiDetectionMode
+	if d := q.Get("romaji"); d != "" {
+		if opts.romaji, err = parseRomajiDetection(d); err != nil {
+			return opts, errors.New("invalid romaji")
+		}
+	}
+	switch l := normalizationLevel(q.Get("normalize")); l {
+	case "", normalizationFull:
+		opts.normalization = normalizationFull
+	case normalizationLegacy:
+		opts.normalization = ""
+	default:
+		return opts, errors.New("invalid normalize")
+	}
+	return opts, nil
+}
+
+// vowels of each fullwidth katakana, used for reading long vowel marks.
+var kanaVowels = func() map[rune]rune {
+	m := make(map[rune]rune)
+	for v, ks := range map[rune]string{
+		'ア': "アカサタナハマヤラワガザダバパァャヮヵ",
+		'イ': "イキシチニヒミリギジヂビピィヰ",
+		'ウ': "ウクスツヌフムユルグズヅブプヴゥュ",
+		'エ': "エケセテネヘメレゲゼデベペェヱヶ",
+		'オ': "オコソトノホモヨロヲゴゾドボポォョ",
+	} {
+		for _, k := range ks {
+			m[k] = v
+		}
+	}
+	return m
+}()
+
+// replaces long vowel marks in the string with the vowel of the preceding kana, if the policy is longVowelVowel.
+// otherwise, the string is returned as is.
+func (opts analyzeOptions) expandLongVowels(s string) string {
+	if opts.longVowel != longVowelVowel || !strings.ContainsAny(s, "ーｰ") {
+		return s
+	}
+
+	rs := []rune(s)
+	prev := rune(0)
+	for i, r := range rs {
+		switch {
+		case r == 'ー' || r == 'ｰ':
+			if v, ok := kanaVowels[prev]; ok {
+				rs[i] = v
+			}
+		case isKana(r):
+			prev = normalizeKanaAt(rs, i)
+		}
+	}
+	return string(rs)
+}
+
+// replaces custom emoji shortcodes with their names if they are to be read (see readCustomEmojiShortcode), or removes them otherwise.
+// shortcodes are read only if readCustomEmoji option is set and they are declared in emoji tags of the event.
+func replaceCustomEmoji(s string, opts analyzeOptions) string {
+	return regexpCustomEmoji.ReplaceAllStringFunc(s, func(sc string) string {
+		if opts.readCustomEmoji && slices.Contains(opts.customEmojis, strings.Trim(sc, ":")) {
+			return readCustomEmojiShortcode(sc)
+		}
+		return " "
+	})
+}
+
+// reads custom emoji shortcode as words (e.g. ":party_parrot:" -> " party parrot ", ":wayo:" -> " ワヨ ").
+// since shortcodes are often romanized Japanese, words not in the English dictionary are read as romaji if possible,
+// by loose detection regardless of the romaji option (so that shortcodes are read in the same way on every endpoint).
+func readCustomEmojiShortcode(sc string) string {
+	words := strings.FieldsFunc(strings.Trim(sc, ":"), func(r rune) bool { return r == '_' })
+	for i, w := range words {
+		if _, ok := getEnWordReading(strings.ToUpper(w)); ok {
+			continue
+		}
+		if r, ok := romajiReading(w, romajiDetectionLoose); ok {
+			words[i] = r
+		}
+	}
+	return " " + strings.Join(words, " ") + " "
+}
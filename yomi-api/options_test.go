@@ -0,0 +1,220 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseV1AnalyzeOptions(t *testing.T) {
+	tests := []struct {
+		query   string
+		wantErr bool
+		want    analyzeOptions
+	}{
+		{query: "", want: analyzeOptions{emoji: emojiRead, romaji: romajiDetectionStrict, normalization: normalizationFull}},
+		{query: "n=2&skipParticles=true", want: analyzeOptions{moraCount: 2, skipParticles: true, emoji: emojiRead, romaji: romajiDetectionStrict, normalization: normalizationFull}},
+		{query: "longVowel=vowel", want: analyzeOptions{longVowel: longVowelVowel, emoji: emojiRead, romaji: romajiDetectionStrict, normalization: normalizationFull}},
+		{query: "longVowel=ignore", want: analyzeOptions{emoji: emojiRead, romaji: romajiDetectionStrict, normalization: normalizationFull}},
+		{query: "enFallback=none", want: analyzeOptions{enFallback: enFallbackNone, emoji: emojiRead, romaji: romajiDetectionStrict, normalization: normalizationFull}},
+		{query: "enFallback=spell", want: analyzeOptions{emoji: emojiRead, romaji: romajiDetectionStrict, normalization: normalizationFull}},
+		{query: "enFallback=guess", want: analyzeOptions{enFallback: enFallbackGuess, emoji: emojiRead, romaji: romajiDetectionStrict, normalization: normalizationFull}},
+		{query: "customEmoji=1", want: analyzeOptions{readCustomEmoji: true, emoji: emojiRead, romaji: romajiDetectionStrict, normalization: normalizationFull}},
+		{query: "emoji=ignore", want: analyzeOptions{emoji: emojiIgnore, romaji: romajiDetectionStrict, normalization: normalizationFull}},
+		{query: "romaji=off", want: analyzeOptions{emoji: emojiRead, romaji: romajiDetectionOff, normalization: normalizationFull}},
+		{query: "romaji=loose", want: analyzeOptions{emoji: emojiRead, romaji: romajiDetectionLoose, normalization: normalizationFull}},
+		{query: "longVowel=keep", wantErr: true},
+		{query: "enFallback=model", wantErr: true},
+		{query: "customEmoji=maybe", wantErr: true},
+		{query: "emoji=skip", wantErr: true},
+		{query: "romaji=auto", wantErr: true},
+		{query: "normalize=legacy", want: analyzeOptions{emoji: emojiRead, romaji: romajiDetectionStrict}},
+		{query: "normalize=nfkc", wantErr: true},
+		{query: "n=0", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		q, _ := url.ParseQuery(tt.query)
+		got, err := parseV1AnalyzeOptions(q)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("parseV1AnalyzeOptions(%q) = %+v; want error", tt.query, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseV1AnalyzeOptions(%q) returned error: %v", tt.query, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseV1AnalyzeOptions(%q) = %+v; want %+v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestParseAnalyzeOptions_ignoresV1Options(t *testing.T) {
+	q, _ := url.ParseQuery("longVowel=vowel&enFallback=none&emoji=ignore&romaji=loose&normalize=full")
+	got, err := parseAnalyzeOptions(q)
+	if err != nil {
+		t.Fatalf("parseAnalyzeOptions returned error: %v", err)
+	}
+	if !reflect.DeepEqual(got, analyzeOptions{}) {
+		t.Errorf("parseAnalyzeOptions = %+v; want zero options", got)
+	}
+}
+
+func TestParseAnalyzeOptions_customEmoji(t *testing.T) {
+	q, _ := url.ParseQuery("customEmoji=true")
+	got, err := parseAnalyzeOptions(q)
+	if err != nil {
+		t.Fatalf("parseAnalyzeOptions returned error: %v", err)
+	}
+	if !reflect.DeepEqual(got, analyzeOptions{readCustomEmoji: true}) {
+		t.Errorf("parseAnalyzeOptions = %+v; want readCustomEmoji", got)
+	}
+}
+
+func TestReplaceCustomEmoji(t *testing.T) {
+	if err := initialize(); err != nil {
+		log.Fatal(err)
+	}
+
+	tests := []struct {
+		in   string
+		opts analyzeOptions
+		want string
+	}{
+		{in: "わよ:wayo:", opts: analyzeOptions{}, want: "わよ "},
+		{in: "わよ:wayo:", opts: analyzeOptions{customEmojis: []string{"wayo"}}, want: "わよ "},
+		{in: "わよ:wayo:", opts: analyzeOptions{readCustomEmoji: true, customEmojis: []string{"wayo"}}, want: "わよ ワヨ "},
+		{in: ":wayo::pizza:", opts: analyzeOptions{readCustomEmoji: true, customEmojis: []string{"pizza"}}, want: "  pizza "},
+		// words in the English dictionary are left to the English reader
+		{in: ":party_parrot:", opts: analyzeOptions{readCustomEmoji: true, customEmojis: []string{"party_parrot"}}, want: " party parrot "},
+		{in: ":wayo_apple:", opts: analyzeOptions{readCustomEmoji: true, customEmojis: []string{"wayo_apple"}}, want: " ワヨ apple "},
+		// undeclared shortcodes are never read
+		{in: ":party_parrot:", opts: analyzeOptions{readCustomEmoji: true}, want: " "},
+	}
+
+	for _, tt := range tests {
+		if got := replaceCustomEmoji(tt.in, tt.opts); got != tt.want {
+			t.Errorf("replaceCustomEmoji(%q, %+v) = %q; want %q", tt.in, tt.opts, got, tt.want)
+		}
+	}
+}
+
+func TestExpandLongVowels(t *testing.T) {
+	opts := analyzeOptions{longVowel: longVowelVowel}
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "コーヒー", want: "コオヒイ"},
+		{in: "ラーメン", want: "ラアメン"},
+		{in: "すーぱー", want: "すウぱア"},
+		{in: "ｺｰﾋｰ", want: "ｺオﾋイ"},
+		{in: "ﾋﾞｰ", want: "ﾋﾞイ"},
+		{in: "ニャー", want: "ニャア"},
+		{in: "ンー", want: "ンー"},
+		{in: "ーあ", want: "ーあ"},
+	}
+
+	for _, tt := range tests {
+		if got := opts.expandLongVowels(tt.in); got != tt.want {
+			t.Errorf("expandLongVowels(%q) = %q; want %q", tt.in, got, tt.want)
+		}
+	}
+	if got := (analyzeOptions{}).expandLongVowels("コーヒー"); got != "コーヒー" {
+		t.Errorf("expandLongVowels with default policy = %q; want as is", got)
+	}
+}
+
+func TestAnalyzeHeadAndLast_options(t *testing.T) {
+	if err := initialize(); err != nil {
+		log.Fatal(err)
+	}
+
+	tests := []struct {
+		in      string
+		opts    analyzeOptions
+		wantErr bool
+		head    rune
+		last    rune
+	}{
+		{in: "コーヒー", opts: analyzeOptions{}, head: 'コ', last: 'ヒ'},
+		{in: "コーヒー", opts: analyzeOptions{longVowel: longVowelVowel}, head: 'コ', last: 'イ'},
+		{in: "珈琲", opts: analyzeOptions{longVowel: longVowelVowel}, head: 'コ', last: 'イ'},
+		{in: "ｽｰﾊﾟｰ", opts: analyzeOptions{longVowel: longVowelVowel}, head: 'ス', last: 'ア'},
+		{in: "りんご", opts: analyzeOptions{longVowel: longVowelVowel}, head: 'リ', last: 'ゴ'},
+		{in: "xqzv", opts: analyzeOptions{}, head: 'エ', last: 'イ'},
+		{in: "xqzv", opts: analyzeOptions{enFallback: enFallbackNone}, wantErr: true},
+		{in: "りんご xqzv", opts: analyzeOptions{enFallback: enFallbackNone}, head: 'リ', last: 'ゴ'},
+		{in: "plebs", opts: analyzeOptions{}, head: 'ピ', last: 'ス'},
+		{in: "plebs", opts: analyzeOptions{enFallback: enFallbackGuess}, head: 'プ', last: 'ス'},
+		{in: "xqzv", opts: analyzeOptions{enFallback: enFallbackGuess}, head: 'エ', last: 'イ'},
+		{in: ":ringo:", opts: analyzeOptions{}, wantErr: true},
+		{in: "たべる:ringo:", opts: analyzeOptions{}, head: 'タ', last: 'ル'},
+		{in: ":apple:", opts: analyzeOptions{readCustomEmoji: true, customEmojis: []string{"apple"}}, head: 'ア', last: 'ル'},
+		{in: "たべる:big_apple:", opts: analyzeOptions{readCustomEmoji: true, customEmojis: []string{"big_apple"}}, head: 'タ', last: 'ル'},
+		{in: ":apple:", opts: analyzeOptions{readCustomEmoji: true}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		hl, err := analyzeHeadAndLast(tt.in, tt.opts)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("analyzeHeadAndLast(%q, %+v) = %c, %c; want error", tt.in, tt.opts, hl.head, hl.last)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("analyzeHeadAndLast(%q, %+v) returned error: %v", tt.in, tt.opts, err)
+			continue
+		}
+		if hl.head != tt.head || hl.last != tt.last {
+			t.Errorf("analyzeHeadAndLast(%q, %+v) = %c, %c; want %c, %c", tt.in, tt.opts, hl.head, hl.last, tt.head, tt.last)
+		}
+	}
+}
+
+func TestHandleHeadLastKana_customEmoji(t *testing.T) {
+	if err := initialize(); err != nil {
+		log.Fatal(err)
+	}
+
+	tests := []struct {
+		path     string
+		readable bool
+		head     rune
+		last     rune
+	}{
+		// shortcodes are read as romaji on the legacy endpoint too
+		{path: "/?c=%3Awayo%3A&customEmoji=true&emojiTag=wayo", readable: true, head: 'ワ', last: 'ヨ'},
+		{path: "/v1/analyze?c=%3Awayo%3A&customEmoji=true&emojiTag=wayo", readable: true, head: 'ワ', last: 'ヨ'},
+		{path: "/?c=%3Awayo%3A&emojiTag=wayo", readable: false},
+		{path: "/?c=%3Awayo%3A&customEmoji=true", readable: false},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+		rec := httptest.NewRecorder()
+		if strings.HasPrefix(tt.path, "/v1/") {
+			handleAnalyze(rec, req)
+		} else {
+			handleHeadLastKana(rec, req)
+		}
+
+		var got HeadLastKanaResp
+		if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+			t.Errorf("GET %s: failed to decode response: %v", tt.path, err)
+			continue
+		}
+		if got.Readable != tt.readable || got.Head != tt.head || got.Last != tt.last {
+			t.Errorf("GET %s = %+v; want readable: %v, head: %c, last: %c", tt.path, got, tt.readable, tt.head, tt.last)
+		}
+	}
+}
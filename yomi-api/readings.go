@@ -0,0 +1,99 @@
+package main
+
+import (
+	"slices"
+
+	"github.com/ikawaha/kagome-dict/dict"
+)
+
+// returns all readings of the word registered in the dictionary, normalized to fullwidth katakana.
+// if pos is given, only readings of entries of the same part of speech are returned (see isSamePOS).
+func dictReadingsOf(word string, pos []string) []string {
+	ri, ok := kagomeDict.ContentsMeta[dict.ReadingIndex]
+	if !ok {
+		return nil
+	}
+
+	var res []string
+	for _, id := range kagomeDict.Index.Search(word) {
+		if id >= len(kagomeDict.Contents) {
+			continue
+		}
+		if pos != nil && !isSamePOS(posNamesOf(id), pos) {
+			continue
+		}
+		// features of a word consist of POS features followed by contents, and the index of reading counts both of them
+		ci := int(ri) - len(kagomeDict.POSTable.POSs[id])
+		if ci < 0 || ci >= len(kagomeDict.Contents[id]) {
+			continue
+		}
+		if r := normalizeKanaString(kagomeDict.Contents[id][ci]); r != "" && !slices.Contains(res, r) {
+			res = append(res, r)
+		}
+	}
+	return res
+}
+
+// returns names of part of speech of the entry in the dictionary.
+func posNamesOf(id int) []string {
+	var res []string
+	for _, p := range kagomeDict.POSTable.POSs[id] {
+		if int(p) < len(kagomeDict.POSTable.NameList) {
+			res = append(res, kagomeDict.POSTable.NameList[p])
+		}
+	}
+	return res
+}
+
+// reports whether the part of speech of the entry is regarded as the same as the one of the token.
+// the major category (e.g. 名詞) must be the same, and proper nouns (固有名詞) must also have the same kind (e.g. 人名, 地名),
+// so that readings of names are not used for common words (e.g. ハジメ for 一).
+func isSamePOS(entry, token []string) bool {
+	if len(entry) == 0 || len(token) == 0 || entry[0] != token[0] {
+		return false
+	}
+	if len(entry) < 2 || entry[1] != "固有名詞" {
+		return true
+	}
+	return len(token) >= 3 && len(entry) >= 3 && token[1] == entry[1] && token[2] == entry[2]
+}
+
+// returns possible readings of the surface form, by splitting it into words in the dictionary in every possible ways and concatenating their readings.
+// at most `limit` readings are returned.
+func surfaceReadingCandidates(surface string, limit int) []string {
+	// memo[i]: possible readings of surface[i:]
+	memo := make(map[int][]string)
+
+	var rec func(i int) []string
+	rec = func(i int) []string {
+		if i == len(surface) {
+			return []string{""}
+		}
+		if m, ok := memo[i]; ok {
+			return m
+		}
+
+		var res []string
+		lens, _ := kagomeDict.Index.CommonPrefixSearch(surface[i:])
+		for _, l := range lens {
+			rests := rec(i + l)
+			if len(rests) == 0 {
+				continue
+			}
+			for _, r := range dictReadingsOf(surface[i:i+l], nil) {
+				for _, rest := range rests {
+					if c := r + rest; !slices.Contains(res, c) {
+						res = append(res, c)
+					}
+					if len(res) >= limit {
+						memo[i] = res
+						return res
+					}
+				}
+			}
+		}
+		memo[i] = res
+		return res
+	}
+	return rec(0)
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// replaces words in the text with their readings in a single pass.
+//
+// words are matched case-insensitively at word boundaries (same as `\b` of regexp), and the longest word is preferred
+// when multiple words match at the same position. so the result doesn't depend on the order of entries.
+type wordReplacer struct {
+	root *wordReplacerNode
+}
+
+type wordReplacerNode struct {
+	children map[rune]*wordReplacerNode
+	// replacement of the word ending at this node. valid only if terminal is true.
+	repl     string
+	terminal bool
+}
+
+// builds a replacer from entries (word -> replacement).
+func newWordReplacer(entries map[string]string) *wordReplacer {
+	root := &wordReplacerNode{}
+	for word, repl := range entries {
+		if word == "" {
+			continue
+		}
+		n := root
+		for _, c := range word {
+			c = unicode.ToUpper(c)
+			if n.children == nil {
+				n.children = make(map[rune]*wordReplacerNode)
+			}
+			next, ok := n.children[c]
+			if !ok {
+				next = &wordReplacerNode{}
+				n.children[c] = next
+			}
+			n = next
+		}
+		n.repl = repl
+		n.terminal = true
+	}
+	return &wordReplacer{root: root}
+}
+
+func (r *wordReplacer) Replace(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+
+	for i := 0; i < len(s); {
+		if end, repl, ok := r.longestMatchAt(s, i); ok {
+			b.WriteString(repl)
+			i = end
+			continue
+		}
+		_, size := utf8.DecodeRuneInString(s[i:])
+		b.WriteString(s[i : i+size])
+		i += size
+	}
+	return b.String()
+}
+
+// finds the longest word starting at s[i:], which is surrounded by word boundaries.
+// returns the end of the word and its replacement.
+func (r *wordReplacer) longestMatchAt(s string, i int) (int, string, bool) {
+	if !isWordBoundary(s, i) {
+		return 0, "", false
+	}
+
+	var (
+		end   int
+		repl  string
+		found bool
+	)
+	n := r.root
+	for j := i; j < len(s); {
+		c, size := utf8.DecodeRuneInString(s[j:])
+		if n = n.children[unicode.ToUpper(c)]; n == nil {
+			break
+		}
+		j += size
+		if n.terminal && isWordBoundary(s, j) {
+			end, repl, found = j, n.repl, true
+		}
+	}
+	return end, repl, found
+}
+
+// checks if s[i] is at a word boundary, in the same manner as `\b` of regexp (ASCII word characters only).
+func isWordBoundary(s string, i int) bool {
+	before := i > 0 && isASCIIWordByte(s[i-1])
+	after := i < len(s) && isASCIIWordByte(s[i])
+	return before != after
+}
+
+func isASCIIWordByte(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || c == '_'
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestWordReplacer(t *testing.T) {
+	r := newWordReplacer(map[string]string{
+		"DON'T":   "ドント",
+		"O'":      "オー",
+		"O'CLOCK": "オクロック",
+		"A'B":     "エービー",
+		"A'BC":    "エービーシー",
+		"C++":     "シープラプラ",
+		"":        "ナシ",
+	})
+
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "don't", want: "ドント"},
+		{in: "I DON'T know", want: "I ドント know"},
+		{in: "Don'ts", want: "Don'ts"},
+		{in: "xdon't", want: "xdon't"},
+		{in: "5 o'clock", want: "5 オクロック"},
+		// word boundary is the same as `\b` of regexp, so a word ending with non-word character needs a word character after it
+		{in: "o'c", want: "オーc"},
+		{in: "a'bc a'b", want: "エービーシー エービー"},
+		{in: "a'bcd", want: "a'bcd"},
+		{in: "日本語don't日本語", want: "日本語ドント日本語"},
+		{in: "c++ c++x", want: "c++ シープラプラx"},
+		{in: "", want: ""},
+	}
+
+	for _, tt := range tests {
+		if got := r.Replace(tt.in); got != tt.want {
+			t.Errorf("Replace(%q) = %q; want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+// the replacer must give the same result as applying regexps of the embedded replace dictionary, as long as entries don't overlap.
+func TestWordReplacer_compatibleWithRegexp(t *testing.T) {
+	if err := initialize(); err != nil {
+		log.Fatal(err)
+	}
+
+	res := regexpReplaceDict(replaceDictEntries)
+	for _, in := range replaceBenchInputs {
+		want := in
+		for re, repl := range res {
+			want = re.ReplaceAllString(want, repl)
+		}
+		if got := replaceDict.Replace(in); got != want {
+			t.Errorf("Replace(%q) = %q; want %q", in, got, want)
+		}
+	}
+}
+
+var replaceBenchInputs = []string{
+	"I don't know what's going on, but it's fine.",
+	"We'll see. They're coming at 5 o'clock, aren't they?",
+	"今日はいい天気ですね",
+	"Let's go! You've got to be kidding, ma'am.",
+	"shouldn't've couldn't wouldn't",
+	"Nostr is a simple, open protocol that enables global, decentralized, and censorship-resistant social media.",
+}
+
+// previous implementation of replace dictionary: applies a regexp for each entry.
+func regexpReplaceDict(entries map[string]string) map[*regexp.Regexp]string {
+	res := make(map[*regexp.Regexp]string, len(entries))
+	for word, repl := range entries {
+		res[regexp.MustCompile(fmt.Sprintf(`\b(?i:%s)\b`, word))] = repl
+	}
+	return res
+}
+
+func BenchmarkReplaceDict_regexp(b *testing.B) {
+	if err := initialize(); err != nil {
+		log.Fatal(err)
+	}
+	res := regexpReplaceDict(replaceDictEntries)
+	in := strings.Join(replaceBenchInputs, " ")
+
+	b.ResetTimer()
+	for range b.N {
+		s := in
+		for re, repl := range res {
+			s = re.ReplaceAllString(s, repl)
+		}
+	}
+}
+
+func BenchmarkReplaceDict_trie(b *testing.B) {
+	if err := initialize(); err != nil {
+		log.Fatal(err)
+	}
+	in := strings.Join(replaceBenchInputs, " ")
+
+	b.ResetTimer()
+	for range b.N {
+		replaceDict.Replace(in)
+	}
+}
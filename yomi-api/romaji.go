@@ -0,0 +1,151 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// heuristic for telling romanized Japanese (romaji) words from English words.
+// romaji detection is applied only to words that are not in the English dictionary.
+type romajiDetection string
+
+const (
+	// romaji is not detected. words not in the English dictionary are read by enFallback strategy.
+	// empty value is the same as this (legacy behavior).
+	romajiDetectionOff romajiDetection = "off"
+	// words spelled in Hepburn/kunrei romaji with 3 or more morae, ending with a vowel or "n", are detected.
+	// following ones are not, since they are likely English words:
+	//   - words in upper case (likely acronyms)
+	//   - words ending like English words with silent e (e.g. time, make)
+	//   - words with syllables only for loanwords (e.g. "fa", "she")
+	romajiDetectionStrict romajiDetection = "strict"
+	// any words that can be read as romaji are detected, including ones with syllables for loanwords.
+	romajiDetectionLoose romajiDetection = "loose"
+)
+
+// default romaji detection of /v1/ endpoints, configured by ROMAJI_DETECTION environment variable.
+// the legacy endpoint never detects romaji.
+var romajiDetectionMode = romajiDetectionStrict
+
+// ending of English words with silent e (vowel, single consonant and "e")
+var regexpEnSilentEEnding = regexp.MustCompile(`[aiueoy][^aiueoy]e$`)
+
+func parseRomajiDetection(s string) (romajiDetection, error) {
+	switch d := romajiDetection(s); d {
+	case romajiDetectionOff, romajiDetectionStrict, romajiDetectionLoose:
+		return d, nil
+	}
+	return "", fmt.Errorf("unknown romaji detection %q", s)
+}
+
+// syllables of Hepburn and kunrei romaji.
+var romajiSyllables = map[string]string{
+	"a": "ア", "i": "イ", "u": "ウ", "e": "エ", "o": "オ",
+	"ka": "カ", "ki": "キ", "ku": "ク", "ke": "ケ", "ko": "コ", "kya": "キャ", "kyu": "キュ", "kyo": "キョ",
+	"sa": "サ", "si": "シ", "su": "ス", "se": "セ", "so": "ソ", "sya": "シャ", "syu": "シュ", "syo": "ショ",
+	"shi": "シ", "sha": "シャ", "shu": "シュ", "sho": "ショ",
+	"ta": "タ", "ti": "チ", "tu": "ツ", "te": "テ", "to": "ト", "tya": "チャ", "tyu": "チュ", "tyo": "チョ",
+	"chi": "チ", "tsu": "ツ", "cha": "チャ", "chu": "チュ", "cho": "チョ",
+	"na": "ナ", "ni": "ニ", "nu": "ヌ", "ne": "ネ", "no": "ノ", "nya": "ニャ", "nyu": "ニュ", "nyo": "ニョ",
+	"ha": "ハ", "hi": "ヒ", "hu": "フ", "he": "ヘ", "ho": "ホ", "hya": "ヒャ", "hyu": "ヒュ", "hyo": "ヒョ",
+	"fu": "フ",
+	"ma": "マ", "mi": "ミ", "mu": "ム", "me": "メ", "mo": "モ", "mya": "ミャ", "myu": "ミュ", "myo": "ミョ",
+	"ya": "ヤ", "yu": "ユ", "yo": "ヨ",
+	"ra": "ラ", "ri": "リ", "ru": "ル", "re": "レ", "ro": "ロ", "rya": "リャ", "ryu": "リュ", "ryo": "リョ",
+	"wa": "ワ", "wo": "ヲ",
+	"ga": "ガ", "gi": "ギ", "gu": "グ", "ge": "ゲ", "go": "ゴ", "gya": "ギャ", "gyu": "ギュ", "gyo": "ギョ",
+	"za": "ザ", "zi": "ジ", "zu": "ズ", "ze": "ゼ", "zo": "ゾ", "zya": "ジャ", "zyu": "ジュ", "zyo": "ジョ",
+	"ji": "ジ", "ja": "ジャ", "ju": "ジュ", "jo": "ジョ",
+	"da": "ダ", "di": "ヂ", "du": "ヅ", "de": "デ", "do": "ド",
+	"ba": "バ", "bi": "ビ", "bu": "ブ", "be": "ベ", "bo": "ボ", "bya": "ビャ", "byu": "ビュ", "byo": "ビョ",
+	"pa": "パ", "pi": "ピ", "pu": "プ", "pe": "ペ", "po": "ポ", "pya": "ピャ", "pyu": "ピュ", "pyo": "ピョ",
+}
+
+// syllables only for loanwords, which are accepted under romajiDetectionLoose.
+var romajiLoanwordSyllables = map[string]string{
+	"fa": "ファ", "fi": "フィ", "fe": "フェ", "fo": "フォ",
+	"she": "シェ", "che": "チェ", "je": "ジェ",
+	"wi": "ウィ", "we": "ウェ", "ye": "イェ",
+	"va": "ヴァ", "vi": "ヴィ", "vu": "ヴ", "ve": "ヴェ", "vo": "ヴォ",
+}
+
+// returns the reading of the word if it is likely a romaji word under the configured heuristic.
+func romajiReading(word string, detection romajiDetection) (string, bool) {
+	if detection == "" || detection == romajiDetectionOff {
+		return "", false
+	}
+	loose := detection == romajiDetectionLoose
+	w := strings.ToLower(word)
+	if !loose {
+		if len(word) >= 2 && word == strings.ToUpper(word) {
+			return "", false
+		}
+		if !strings.ContainsRune("aiueon", rune(w[len(w)-1])) || regexpEnSilentEEnding.MatchString(w) {
+			return "", false
+		}
+	}
+
+	r, ok := romajiToKana(w, loose)
+	if !ok || !loose && countMorae(r) < 3 {
+		return "", false
+	}
+	return r, true
+}
+
+// converts the romaji word (in lower case) to katakana. returns false if the word can't be read as romaji.
+func romajiToKana(w string, withLoanwords bool) (string, bool) {
+	var b strings.Builder
+	for i := 0; i < len(w); {
+		// syllabic n: before a consonant (other than y) or at the end. "m" before labials is also read as "ン" (e.g. shimbun)
+		if w[i] == 'n' && (i+1 == len(w) || !strings.ContainsRune("aiueoy", rune(w[i+1]))) ||
+			w[i] == 'm' && i+1 < len(w) && strings.ContainsRune("bp", rune(w[i+1])) {
+			b.WriteString("ン")
+			i++
+			continue
+		}
+		// geminate consonant (e.g. kk, tt, tch)
+		if i+1 < len(w) && (w[i] == w[i+1] && isGeminableConsonant(w[i], withLoanwords) || w[i:i+2] == "tc" || w[i:i+2] == "cc") {
+			b.WriteString("ッ")
+			i++
+			continue
+		}
+
+		matched := false
+		for l := min(3, len(w)-i); l >= 1; l-- {
+			k, ok := romajiSyllables[w[i:i+l]]
+			if !ok && withLoanwords {
+				k, ok = romajiLoanwordSyllables[w[i:i+l]]
+			}
+			if ok {
+				b.WriteString(k)
+				i += l
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return "", false
+		}
+	}
+	return b.String(), true
+}
+
+func isGeminableConsonant(c byte, withLoanwords bool) bool {
+	if withLoanwords {
+		return strings.IndexByte("kstpgzdbhfjrw", c) >= 0
+	}
+	return strings.IndexByte("kstp", c) >= 0
+}
+
+// counts morae of the katakana string. small ya/yu/yo and vowels don't make morae by themselves.
+func countMorae(s string) int {
+	n := utf8.RuneCountInString(s)
+	for _, r := range s {
+		if strings.ContainsRune("ャュョァィゥェォ", r) {
+			n--
+		}
+	}
+	return n
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"log"
+	"testing"
+)
+
+func TestRomajiToKana(t *testing.T) {
+	tests := []struct {
+		in            string
+		withLoanwords bool
+		want          string
+		wantOk        bool
+	}{
+		{in: "arigatou", want: "アリガトウ", wantOk: true},
+		{in: "sugoi", want: "スゴイ", wantOk: true},
+		{in: "konnichiwa", want: "コンニチワ", wantOk: true},
+		{in: "tukue", want: "ツクエ", wantOk: true},
+		{in: "sinbun", want: "シンブン", wantOk: true},
+		{in: "shimbun", want: "シンブン", wantOk: true},
+		{in: "matcha", want: "マッチャ", wantOk: true},
+		{in: "kitte", want: "キッテ", wantOk: true},
+		{in: "kyouto", want: "キョウト", wantOk: true},
+		{in: "wayo", want: "ワヨ", wantOk: true},
+		{in: "fairu", want: "", wantOk: false},
+		{in: "fairu", withLoanwords: true, want: "ファイル", wantOk: true},
+		{in: "hello", want: "", wantOk: false},
+		{in: "nostr", want: "", wantOk: false},
+		{in: "cake", want: "", wantOk: false},
+	}
+
+	for _, tt := range tests {
+		got, ok := romajiToKana(tt.in, tt.withLoanwords)
+		if got != tt.want || ok != tt.wantOk {
+			t.Errorf("romajiToKana(%q, %v) = %q, %v; want %q, %v", tt.in, tt.withLoanwords, got, ok, tt.want, tt.wantOk)
+		}
+	}
+}
+
+func TestRomajiReading(t *testing.T) {
+	tests := []struct {
+		detection romajiDetection
+		in        string
+		want      string
+		wantOk    bool
+	}{
+		{detection: romajiDetectionStrict, in: "Sugoi", want: "スゴイ", wantOk: true},
+		{detection: romajiDetectionStrict, in: "ramen", want: "ラメン", wantOk: true},
+		// acronyms, short words and words ending with consonants are not romaji under strict detection
+		{detection: romajiDetectionStrict, in: "ANA", wantOk: false},
+		{detection: romajiDetectionStrict, in: "ne", wantOk: false},
+		{detection: romajiDetectionStrict, in: "wayo", wantOk: false},
+		{detection: romajiDetectionStrict, in: "fairu", wantOk: false},
+		{detection: romajiDetectionStrict, in: "sukiyakit", wantOk: false},
+		// English words with silent e are not romaji under strict detection
+		{detection: romajiDetectionStrict, in: "time", wantOk: false},
+		{detection: romajiDetectionStrict, in: "make", wantOk: false},
+		{detection: romajiDetectionStrict, in: "joke", wantOk: false},
+		{detection: romajiDetectionStrict, in: "huge", wantOk: false},
+		{detection: romajiDetectionLoose, in: "ANA", want: "アナ", wantOk: true},
+		{detection: romajiDetectionLoose, in: "ne", want: "ネ", wantOk: true},
+		{detection: romajiDetectionLoose, in: "fairu", want: "ファイル", wantOk: true},
+		{detection: romajiDetectionOff, in: "sugoi", wantOk: false},
+		{in: "sugoi", wantOk: false},
+	}
+
+	for _, tt := range tests {
+		got, ok := romajiReading(tt.in, tt.detection)
+		if got != tt.want || ok != tt.wantOk {
+			t.Errorf("romajiReading(%q) under %q = %q, %v; want %q, %v", tt.in, tt.detection, got, ok, tt.want, tt.wantOk)
+		}
+	}
+}
+
+func TestAnalyzeHeadAndLast_romaji(t *testing.T) {
+	if err := initialize(); err != nil {
+		log.Fatal(err)
+	}
+
+	tests := []struct {
+		in   string
+		opts analyzeOptions
+		head rune
+		last rune
+	}{
+		{in: "arigatou", opts: analyzeOptions{romaji: romajiDetectionStrict}, head: 'ア', last: 'ウ'},
+		{in: "konnichiwa", opts: analyzeOptions{romaji: romajiDetectionStrict}, head: 'コ', last: 'ワ'},
+		// words in the English dictionary take precedence
+		{in: "tone", opts: analyzeOptions{romaji: romajiDetectionStrict}, head: 'ト', last: 'ン'},
+		{in: ":wayo:", opts: analyzeOptions{readCustomEmoji: true, customEmojis: []string{"wayo"}}, head: 'ワ', last: 'ヨ'},
+		// the legacy endpoint does not detect romaji
+		{in: "arigatou", head: 'エ', last: 'ユ'},
+	}
+
+	for _, tt := range tests {
+		hl, err := analyzeHeadAndLast(tt.in, tt.opts)
+		if err != nil {
+			t.Errorf("analyzeHeadAndLast(%q) returned error: %v", tt.in, err)
+			continue
+		}
+		if hl.head != tt.head || hl.last != tt.last {
+			t.Errorf("analyzeHeadAndLast(%q) = %c, %c, want %c, %c", tt.in, hl.head, hl.last, tt.head, tt.last)
+		}
+	}
+}
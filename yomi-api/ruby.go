@@ -0,0 +1,51 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	// ｜漢字《かんじ》, |漢字《かんじ》: base text is explicitly delimited by the vertical bar
+	regexpRubyWithBar = regexp.MustCompile(`[|｜]([^|｜《》]+)《([^《》]*)》`)
+	// {漢字|かんじ}
+	regexpRubyBrace = regexp.MustCompile(`\{([^{}|]+)\|([^{}|]*)\}`)
+	// 漢字《かんじ》: base text is the continuous kanji just before the ruby
+	regexpRubyWithoutBar = regexp.MustCompile(`([\p{Han}々〆ヵヶ]+)《([^《》]*)》`)
+
+	regexpRubyReading = regexp.MustCompile(`^[ぁ-ゖァ-ヶー]+$`)
+)
+
+// replaces inline ruby notations in the text with their readings.
+//
+// supported notations are:
+//   - 漢字《かんじ》
+//   - ｜漢字《かんじ》 (or with halfwidth bar "|")
+//   - {漢字|かんじ}
+//
+// readings are converted to katakana, so that kagome tokenizer treats them as single words.
+// if the reading is not kana (e.g. "{漢字|kanji}"), ruby markup is just stripped and the base text is left.
+func replaceInlineRuby(s string) string {
+	res := s
+	for _, re := range []*regexp.Regexp{regexpRubyWithBar, regexpRubyBrace, regexpRubyWithoutBar} {
+		res = re.ReplaceAllStringFunc(res, func(m string) string {
+			sm := re.FindStringSubmatch(m)
+			base, reading := sm[1], strings.TrimSpace(sm[2])
+			if !regexpRubyReading.MatchString(reading) {
+				return base
+			}
+			return hiraganaToKatakana(reading)
+		})
+	}
+	return res
+}
+
+// converts hiragana in the string to fullwidth katakana. other characters are left as is.
+func hiraganaToKatakana(s string) string {
+	return strings.Map(func(r rune) rune {
+		if isHiragana(r) {
+			return r + 0x60
+		}
+		return r
+	}, s)
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"log"
+	"testing"
+)
+
+func TestReplaceInlineRuby(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "漢字《かんじ》", want: "カンジ"},
+		{in: "これは漢字《かんじ》です", want: "これはカンジです"},
+		{in: "｜山田太郎《やまだたろう》", want: "ヤマダタロウ"},
+		{in: "こんにちは|Nostr《のすたー》", want: "こんにちはノスター"},
+		{in: "{漢字|かんじ}と{仮名|カナ}", want: "カンジとカナ"},
+		{in: "{漢字|kanji}", want: "漢字"},
+		{in: "強敵《とも》", want: "トモ"},
+		{in: "これは《かっこ》", want: "これは《かっこ》"},
+		{in: "《》", want: "《》"},
+	}
+
+	for _, tt := range tests {
+		if got := replaceInlineRuby(tt.in); got != tt.want {
+			t.Errorf("replaceInlineRuby(%q) = %q; want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestEffectiveHeadAndLast_inlineRuby(t *testing.T) {
+	if err := initialize(); err != nil {
+		log.Fatal(err)
+	}
+
+	tests := []struct {
+		in   string
+		head rune
+		last rune
+	}{
+		{in: "強敵《とも》", head: 'ト', last: 'モ'},
+		{in: "今日は｜寿司《すし》", head: 'キ', last: 'シ'},
+		{in: "{日本|にっぽん}", head: 'ニ', last: 'ン'},
+		{in: "{漢字|kanji}", head: 'カ', last: 'ジ'},
+	}
+
+	for _, tt := range tests {
+		hl, err := analyzeHeadAndLast(tt.in, analyzeOptions{normalization: normalizationFull})
+		if err != nil {
+			t.Errorf("analyzeHeadAndLast(%q) got unexpected error: %v", tt.in, err)
+			continue
+		}
+		if hl.head != tt.head || hl.last != tt.last {
+			t.Errorf("analyzeHeadAndLast(%q) = %q, %q; want %q, %q", tt.in, hl.head, hl.last, tt.head, tt.last)
+		}
+	}
+}
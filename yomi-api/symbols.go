@@ -0,0 +1,160 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// replaces symbols and units with their readings.
+//
+// entries containing ASCII alphabets are units (e.g. km, kg), which are read only if they directly follow numbers,
+// so that they are not confused with English words. other entries are symbols (e.g. ℃, ＋, ☆), which are read wherever they appear.
+// both are matched case-sensitively, and the longest entry is preferred.
+type symbolReplacer struct {
+	entries map[string]string
+	// max length of entries in runes
+	maxLen int
+}
+
+func newSymbolReplacer(entries map[string]string) *symbolReplacer {
+	maxLen := 0
+	for word := range entries {
+		maxLen = max(maxLen, utf8.RuneCountInString(word))
+	}
+	return &symbolReplacer{entries: entries, maxLen: maxLen}
+}
+
+func isUnitEntry(word string) bool {
+	return strings.IndexFunc(word, func(r rune) bool { return 'a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' }) >= 0
+}
+
+// replaces numbers followed by units with their readings, applying sound changes as with counters (e.g. 1kg -> イッキログラム, 10min -> ジュップン).
+// if the number can't be read, only the unit is replaced, and the number is left to later stages.
+func (r *symbolReplacer) ReplaceUnits(s string) string {
+	accept := func(s string, start, end int) bool {
+		return isUnitEntry(s[start:end]) && start > 0 && isASCIIDigit(s[start-1]) && (end == len(s) || !isASCIIWordByte(s[end]))
+	}
+
+	var b strings.Builder
+	prev := 0
+	for i := 0; i < len(s); {
+		end, repl, ok := r.longestMatchAt(s, i, accept)
+		if !ok {
+			_, size := utf8.DecodeRuneInString(s[i:])
+			i += size
+			continue
+		}
+
+		start := numberStartBefore(s, i)
+		b.WriteString(s[prev:start])
+		num, sign := s[start:i], ""
+		if cut, isNeg := strings.CutPrefix(num, "-"); isNeg {
+			num, sign = cut, "マイナス"
+		}
+		if nr, ok := numberWithCounter(num, unitCounter(repl)); ok {
+			b.WriteString(sign + nr)
+		} else {
+			b.WriteString(s[start:i] + repl)
+		}
+		prev, i = end, end
+	}
+	b.WriteString(s[prev:])
+	return b.String()
+}
+
+// returns the start of the number (including the minus sign, as with regexpNumber) which ends at s[end].
+func numberStartBefore(s string, end int) int {
+	start := end
+	for start > 0 && (isASCIIDigit(s[start-1]) || strings.IndexByte(",_.", s[start-1]) >= 0) {
+		start--
+	}
+	// separators at the beginning are not a part of the number
+	for start < end && strings.IndexByte(",_.", s[start]) >= 0 {
+		start++
+	}
+	if start > 0 && s[start-1] == '-' {
+		start--
+	}
+	return start
+}
+
+// counter words of the same readings as units, whose sound changes are used for the units (e.g. min: フン -> 10分: ジュップン).
+var unitCounterWords = map[string]string{"フン": "分", "ビョウ": "秒", "ジカン": "時間"}
+
+// returns the counter for reading numbers followed by the unit.
+// sound changes of units other than unitCounterWords are determined by the head of their readings (e.g. イッキロ, イッセンチ, イッポンド),
+// except ones starting with h, which don't change since most of units are loanwords (e.g. イチヘルツ).
+func unitCounter(reading string) counter {
+	if w, ok := unitCounterWords[reading]; ok {
+		return counters[w]
+	}
+
+	c := counter{reading: reading}
+	switch head, _ := utf8.DecodeRuneInString(reading); {
+	case strings.ContainsRune("カキクケコ", head):
+		c.sokuonEndings = sokuonEndingsHK
+	case strings.ContainsRune("サシスセソタチツテト", head):
+		c.sokuonEndings = sokuonEndingsST
+	case strings.ContainsRune("パピプペポ", head):
+		c.sokuonEndings = sokuonEndingsP
+	}
+	return c
+}
+
+// replaces symbols other than units (e.g. ℃ -> ド).
+func (r *symbolReplacer) ReplaceSymbols(s string) string {
+	return r.replace(s, func(s string, start, end int) bool {
+		return !isUnitEntry(s[start:end])
+	})
+}
+
+// replaces the longest entries starting at each position, for which accept returns true.
+func (r *symbolReplacer) replace(s string, accept func(s string, start, end int) bool) string {
+	if len(r.entries) == 0 {
+		return s
+	}
+
+	var b strings.Builder
+	b.Grow(len(s))
+	for i := 0; i < len(s); {
+		if end, repl, ok := r.longestMatchAt(s, i, accept); ok {
+			b.WriteString(repl)
+			i = end
+			continue
+		}
+		_, size := utf8.DecodeRuneInString(s[i:])
+		b.WriteString(s[i : i+size])
+		i += size
+	}
+	return b.String()
+}
+
+func (r *symbolReplacer) longestMatchAt(s string, i int, accept func(s string, start, end int) bool) (int, string, bool) {
+	// collect candidate ends, then try them from the longest
+	ends := make([]int, 0, r.maxLen)
+	for j, n := i, 0; j < len(s) && n < r.maxLen; n++ {
+		_, size := utf8.DecodeRuneInString(s[j:])
+		j += size
+		ends = append(ends, j)
+	}
+	for k := len(ends) - 1; k >= 0; k-- {
+		end := ends[k]
+		if repl, ok := r.entries[s[i:end]]; ok && accept(s, i, end) {
+			return end, repl, true
+		}
+	}
+	return 0, "", false
+}
+
+func isASCIIDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+// currency symbols placed before numbers (e.g. $100)
+var regexpPrefixedCurrency = regexp.MustCompile(`([$¥￥€£₩])([0-9](?:[0-9,_.]*[0-9])?)`)
+
+// moves currency symbols placed before numbers to after them, so that they are read in Japanese order (e.g. $100 -> 100$ -> ヒャクドル).
+func movePrefixedCurrencySymbols(s string) string {
+	return regexpPrefixedCurrency.ReplaceAllString(s, "$2$1")
+}
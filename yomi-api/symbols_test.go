@@ -0,0 +1,112 @@
+package main
+
+import (
+	"log"
+	"testing"
+)
+
+func TestSymbolReplacer(t *testing.T) {
+	r := newSymbolReplacer(map[string]string{
+		"km":  "キロメートル",
+		"m":   "メートル",
+		"cm":  "センチメートル",
+		"mL":  "ミリリットル",
+		"kg":  "キログラム",
+		"Hz":  "ヘルツ",
+		"min": "フン",
+		"h":   "ジカン",
+		"℃":   "ド",
+		"＋":   "プラス",
+		"+":   "プラス",
+	})
+
+	unitTests := []struct {
+		in   string
+		want string
+	}{
+		{in: "5km", want: "ゴキロメートル"},
+		{in: "100m走", want: "ヒャクメートル走"},
+		{in: "200mL", want: "ニヒャクミリリットル"},
+		{in: "200ML", want: "200ML"},
+		{in: "1,000m", want: "センメートル"},
+		{in: "-5km", want: "マイナスゴキロメートル"},
+		{in: "高さ1.5m", want: "高さイッテンゴメートル"},
+		// sound changes as with counters
+		{in: "1kg", want: "イッキログラム"},
+		{in: "6kg", want: "ロッキログラム"},
+		{in: "8cm", want: "ハッセンチメートル"},
+		{in: "10min", want: "ジュップン"},
+		{in: "3min", want: "サンプン"},
+		{in: "4h", want: "ヨジカン"},
+		{in: "1Hz", want: "イチヘルツ"},
+		// units must follow numbers directly
+		{in: "km", want: "km"},
+		{in: "5 km", want: "5 km"},
+		{in: "5kmh", want: "5kmh"},
+		// symbols are not replaced in this stage
+		{in: "20℃", want: "20℃"},
+	}
+	for _, tt := range unitTests {
+		if got := r.ReplaceUnits(tt.in); got != tt.want {
+			t.Errorf("ReplaceUnits(%s) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+
+	symbolTests := []struct {
+		in   string
+		want string
+	}{
+		{in: "ニジュウ℃", want: "ニジュウド"},
+		{in: "イチ＋イチ", want: "イチプラスイチ"},
+		{in: "c+", want: "cプラス"},
+		{in: "km", want: "km"},
+	}
+	for _, tt := range symbolTests {
+		if got := r.ReplaceSymbols(tt.in); got != tt.want {
+			t.Errorf("ReplaceSymbols(%s) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestMovePrefixedCurrencySymbols(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "$100", want: "100$"},
+		{in: "¥1,000です", want: "1,000¥です"},
+		{in: "€1.5", want: "1.5€"},
+		{in: "$", want: "$"},
+	}
+
+	for _, tt := range tests {
+		if got := movePrefixedCurrencySymbols(tt.in); got != tt.want {
+			t.Errorf("movePrefixedCurrencySymbols(%s) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeText_symbols(t *testing.T) {
+	if err := initialize(); err != nil {
+		log.Fatal(err)
+	}
+
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "5km", want: "ゴキロメートル"},
+		{in: "1kg", want: "イッキログラム"},
+		{in: "10min", want: "ジュップン"},
+		{in: "気温20℃", want: "気温ニジュウド"},
+		{in: "$100", want: "ヒャクドル"},
+		{in: "1＋1＝2", want: "イチプラスイチイコールニ"},
+		{in: "☆", want: "ホシ"},
+	}
+
+	for _, tt := range tests {
+		if got := normalizeText(tt.in, analyzeOptions{normalization: normalizationFull}); got != tt.want {
+			t.Errorf("normalizeText(%s) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
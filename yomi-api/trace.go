@@ -0,0 +1,150 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/ikawaha/kagome/v2/tokenizer"
+)
+
+// trace of the process of determining reading of the text.
+type TraceResp struct {
+	// name of the system dictionary used for tokenization
+	Dict       string       `json:"dict"`
+	Input      string       `json:"input"`
+	Normalized string       `json:"normalized"`
+	Tokens     []TraceToken `json:"tokens"`
+	// whole reading of the text in fullwidth katakana. long vowel marks (ー) are kept, since morae need them
+	Reading string `json:"reading"`
+
+	Readable bool   `json:"readable"`
+	Head     rune   `json:"head,omitempty"`
+	Last     rune   `json:"last,omitempty"`
+	Reason   string `json:"reason,omitempty"`
+	Error    string `json:"error,omitempty"`
+
+	// traces with other dictionaries, if requested
+	Comparisons []TraceResp `json:"comparisons,omitempty"`
+}
+
+type TraceToken struct {
+	Surface string   `json:"surface"`
+	POS     []string `json:"pos,omitempty"`
+	// reading of the token in the dictionary
+	DictReading string `json:"dictReading,omitempty"`
+
+	// reading of the token used for shiritori, and its source
+	Kana       string     `json:"kana,omitempty"`
+	KanaSource kanaSource `json:"kanaSource,omitempty"`
+
+	HeadKana   rune       `json:"headKana,omitempty"`
+	HeadSource kanaSource `json:"headSource,omitempty"`
+	LastKana   rune       `json:"lastKana,omitempty"`
+	LastSource kanaSource `json:"lastSource,omitempty"`
+
+	// whether head/last kana of the text is derived from this token
+	IsHead bool `json:"isHead,omitempty"`
+	IsLast bool `json:"isLast,omitempty"`
+}
+
+// returns the trace of each stage of determining reading of the text.
+// options are specified by query parameters, just like GET /v1/analyze.
+// additionally, traces with other dictionaries can be requested by the query parameter "compare" (comma-separated names of dictionaries).
+func handleTrace(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		_, _ = fmt.Fprintf(w, "method not allowed")
+		return
+	}
+
+	opts, err := parseV1AnalyzeOptions(r.URL.Query())
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = fmt.Fprint(w, err.Error())
+		return
+	}
+	opts.readingHint = r.URL.Query().Get("reading")
+	opts.customEmojis = r.URL.Query()["emojiTag"]
+	content := r.URL.Query().Get("c")
+
+	resp := traceReading(content, opts)
+	if cq := r.URL.Query().Get("compare"); cq != "" {
+		for _, name := range strings.Split(cq, ",") {
+			tok, err := tokenizerOf(kagomeDictName(name))
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = fmt.Fprint(w, err.Error())
+				return
+			}
+			resp.Comparisons = append(resp.Comparisons, traceReadingWith(content, opts, kagomeDictName(name), tok))
+		}
+	}
+
+	jenc := json.NewEncoder(w)
+	jenc.SetIndent("", "")
+	_ = jenc.Encode(resp)
+}
+
+func traceReading(s string, opts analyzeOptions) TraceResp {
+	return traceReadingWith(s, opts, tokenizerDictName, currentTokenizer())
+}
+
+// traces the process of determining reading of the text, tokenizing with the tokenizer using the dictionary of the name.
+func traceReadingWith(s string, opts analyzeOptions, dictName kagomeDictName, tok *tokenizer.Tokenizer) TraceResp {
+	normalized := normalizeText(s, opts)
+	tokens := tok.Analyze(normalized, tokenizeMode)
+
+	resp := TraceResp{
+		Dict:       string(dictName),
+		Input:      s,
+		Normalized: normalized,
+		Tokens:     make([]TraceToken, len(tokens)),
+	}
+
+	var hl *headLastResult
+	err := checkReadability(s, opts)
+	if err == nil {
+		hl, err = analyzeTokens(normalized, tokens, opts)
+	}
+	if err != nil {
+		resp.Error = err.Error()
+		resp.Reason = string(unreadableReasonOf(err))
+	} else {
+		resp.Readable = true
+		resp.Head = hl.head
+		resp.Last = hl.last
+	}
+
+	var reading strings.Builder
+	for i, t := range tokens {
+		tt := TraceToken{
+			Surface: t.Surface,
+			POS:     t.POS(),
+		}
+		if r, ok := readingOfToken(t); ok && r != "*" {
+			tt.DictReading = r
+		}
+
+		if hl != nil && hl.hintedReadings != nil {
+			tt.Kana, tt.KanaSource = hl.hintedReadings[i], kanaSourceHint
+			if tt.Kana != "" {
+				tt.HeadKana, tt.HeadSource = headKana(tt.Kana), kanaSourceHint
+				tt.LastKana, tt.LastSource = lastKana(tt.Kana), kanaSourceHint
+			}
+		} else {
+			tt.Kana, tt.KanaSource = kanaReadingOfToken(t, opts)
+			tt.HeadKana, tt.HeadSource = headKanaOfToken(t, opts)
+			tt.LastKana, tt.LastSource = lastKanaOfToken(t, opts)
+		}
+		if hl != nil {
+			tt.IsHead = i == hl.headIdx
+			tt.IsLast = i == hl.lastIdx
+		}
+		reading.WriteString(tt.Kana)
+		resp.Tokens[i] = tt
+	}
+	resp.Reading = reading.String()
+	return resp
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestTraceReading(t *testing.T) {
+	if err := initialize(); err != nil {
+		log.Fatal(err)
+	}
+
+	type tokenWant struct {
+		surface    string
+		headSource kanaSource
+		lastSource kanaSource
+	}
+	tests := []struct {
+		in         string
+		opts       analyzeOptions
+		normalized string
+		reading    string
+		readable   bool
+		tokens     []tokenWant
+	}{
+		{
+			in:         "ｳﾜｰ漢字",
+			normalized: "ｳﾜｰ漢字",
+			reading:    "ウワーカンジ",
+			readable:   true,
+			tokens: []tokenWant{
+				{surface: "ｳﾜｰ", headSource: kanaSourceHalfwidth, lastSource: kanaSourceHalfwidth},
+				{surface: "漢字", headSource: kanaSourceReading, lastSource: kanaSourceReading},
+			},
+		},
+		{
+			in:         "nostr qzx",
+			normalized: "nostr qzx",
+			reading:    "ノスターキューゼットエックス",
+			readable:   true,
+			tokens: []tokenWant{
+				{surface: "nostr", headSource: kanaSourceEnDict, lastSource: kanaSourceEnDict},
+				{surface: " "},
+				{surface: "qzx", headSource: kanaSourceAlphabet, lastSource: kanaSourceAlphabet},
+			},
+		},
+		{
+			in:         "plebs qzx",
+			opts:       analyzeOptions{enFallback: enFallbackGuess},
+			normalized: "plebs qzx",
+			reading:    "プレブスキューゼットエックス",
+			readable:   true,
+			tokens: []tokenWant{
+				{surface: "plebs", headSource: kanaSourceGuessed, lastSource: kanaSourceGuessed},
+				{surface: " "},
+				{surface: "qzx", headSource: kanaSourceAlphabet, lastSource: kanaSourceAlphabet},
+			},
+		},
+		{
+			in:         "日本",
+			opts:       analyzeOptions{readingHint: "ニッポン"},
+			normalized: "日本",
+			reading:    "ニッポン",
+			readable:   true,
+			tokens: []tokenWant{
+				{surface: "日本", headSource: kanaSourceHint, lastSource: kanaSourceHint},
+			},
+		},
+		{
+			in:         "！？",
+			normalized: "！？",
+			reading:    "",
+			readable:   false,
+			tokens: []tokenWant{
+				{surface: "！"},
+				{surface: "？"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		got := traceReading(tt.in, tt.opts)
+		if got.Normalized != tt.normalized || got.Reading != tt.reading || got.Readable != tt.readable {
+			t.Errorf("traceReading(%q) = {normalized: %q, reading: %q, readable: %v}; want {%q, %q, %v}", tt.in, got.Normalized, got.Reading, got.Readable, tt.normalized, tt.reading, tt.readable)
+		}
+		if len(got.Tokens) != len(tt.tokens) {
+			t.Errorf("traceReading(%q) got %d tokens; want %d", tt.in, len(got.Tokens), len(tt.tokens))
+			continue
+		}
+		for i, w := range tt.tokens {
+			g := got.Tokens[i]
+			if g.Surface != w.surface || g.HeadSource != w.headSource || g.LastSource != w.lastSource {
+				t.Errorf("traceReading(%q).Tokens[%d] = {%q, %q, %q}; want {%q, %q, %q}", tt.in, i, g.Surface, g.HeadSource, g.LastSource, w.surface, w.headSource, w.lastSource)
+			}
+		}
+	}
+}
+
+func TestHandleTrace_compare(t *testing.T) {
+	if err := initialize(); err != nil {
+		log.Fatal(err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/trace?c="+url.QueryEscape("公園に行く")+"&compare=ipa,uni", nil)
+	rec := httptest.NewRecorder()
+	handleTrace(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d; want %d", rec.Code, http.StatusOK)
+	}
+	var got TraceResp
+	if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if got.Dict != string(tokenizerDictName) || len(got.Comparisons) != 2 || got.Comparisons[0].Dict != "ipa" || got.Comparisons[1].Dict != "uni" {
+		t.Errorf("trace = %+v; want traces with ipa and uni as comparisons", got)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/v1/trace?c=a&compare=unknown", nil)
+	rec = httptest.NewRecorder()
+	handleTrace(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("status = %d; want %d", rec.Code, http.StatusBadRequest)
+	}
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// explicit mappings of characters that are not covered (or not covered well) by Unicode normalization forms.
+var unicodeKanaMappings = map[rune]string{
+	// katakana with dakuten that have no kana-only equivalent
+	'ヷ': "ヴァ",
+	'ヸ': "ヴィ",
+	'ヹ': "ヴェ",
+	'ヺ': "ヴォ",
+	// ligatures (NFKC maps them too, but they are not in the blocks to which NFKC is applied)
+	'ゟ': "より",
+	'ヿ': "コト",
+	// small katakana for Ainu, which are read as the normal-sized ones
+	'ㇰ': "ク",
+	'ㇱ': "シ",
+	'ㇲ': "ス",
+	'ㇳ': "ト",
+	'ㇴ': "ヌ",
+	'ㇵ': "ハ",
+	'ㇶ': "ヒ",
+	'ㇷ': "フ",
+	'ㇸ': "ヘ",
+	'ㇹ': "ホ",
+	'ㇺ': "ム",
+	'ㇻ': "ラ",
+	'ㇼ': "リ",
+	'ㇽ': "ル",
+	'ㇾ': "レ",
+	'ㇿ': "ロ",
+}
+
+// spacing (han-)dakuten to combining ones
+var combiningDakuten = map[rune]rune{
+	'゛': '\u3099',
+	'゜': '\u309A',
+}
+
+// normalizes Unicode representation of the text, so that kana are represented in the way the rest of the process expects.
+//
+// the stage consists of:
+//   - composing kana and following (han-)dakuten, both combining (U+3099/U+309A, e.g. from macOS) and spacing (゛/゜) ones
+//     (NFC; spacing ones following a non-kana are left as is)
+//   - decomposing enclosed and squared characters (e.g. ㋐ -> ア, ㌔ -> キロ, ㍉ -> ミリ) (NFKC, only for these blocks)
+//   - mapping archaic, ligature and small katakana that have no canonical equivalents (e.g. ヷ -> ヴァ, ㇰ -> ク)
+//
+// NFKC is not applied to the whole text, because it also changes halfwidth kana, fullwidth alphabets and symbols, which are handled in later stages.
+func normalizeUnicode(s string) string {
+	if strings.ContainsAny(s, "゛゜") {
+		s = composeSpacingDakuten(s)
+	}
+	s = norm.NFC.String(s)
+
+	var b strings.Builder
+	b.Grow(len(s))
+	for _, r := range s {
+		if m, ok := unicodeKanaMappings[r]; ok {
+			b.WriteString(m)
+			continue
+		}
+		if isEnclosedCJK(r) {
+			b.WriteString(norm.NFKC.String(string(r)))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// replaces spacing (han-)dakuten following fullwidth kana with the combining ones, so that NFC can compose them.
+func composeSpacingDakuten(s string) string {
+	rs := []rune(s)
+	var b strings.Builder
+	b.Grow(len(s))
+	for i, r := range rs {
+		if c, ok := combiningDakuten[r]; ok && i > 0 && (isHiragana(rs[i-1]) || isFullwidthKatakana(rs[i-1])) {
+			b.WriteRune(c)
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// Enclosed CJK Letters and Months (U+3200-U+32FF) and CJK Compatibility (U+3300-U+33FF).
+// e.g. ㋐, ㈱, ㉑, ㌔, ㍉, ㍻
+func isEnclosedCJK(r rune) bool {
+	return 0x3200 <= r && r <= 0x33FF
+}
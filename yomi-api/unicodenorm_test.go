@@ -0,0 +1,66 @@
+package main
+
+import (
+	"log"
+	"testing"
+)
+
+func TestNormalizeUnicode(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "あいうえお", want: "あいうえお"},
+		{in: "がぎ", want: "がぎ"},
+		{in: "パン", want: "パン"},
+		{in: "ヴァイオリン", want: "ヴァイオリン"},
+		{in: "か゛ぎ", want: "がぎ"},
+		{in: "ホ゜ン", want: "ポン"},
+		{in: "a゛", want: "a゛"},
+		{in: "ン゛", want: "ン゙"},
+		{in: "㋐㋑㋒", want: "アイウ"},
+		{in: "㌔㍉", want: "キロミリ"},
+		{in: "㈱", want: "(株)"},
+		{in: "ヷヸヹヺ", want: "ヴァヴィヴェヴォ"},
+		{in: "ゟヿ", want: "よりコト"},
+		{in: "カㇰ", want: "カク"},
+		{in: "ｶﾞ", want: "ｶﾞ"},
+		{in: "ＡＢＣ", want: "ＡＢＣ"},
+	}
+
+	for _, tt := range tests {
+		if got := normalizeUnicode(tt.in); got != tt.want {
+			t.Errorf("normalizeUnicode(%q) = %q; want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestEffectiveHeadAndLast_unicode(t *testing.T) {
+	if err := initialize(); err != nil {
+		log.Fatal(err)
+	}
+
+	tests := []struct {
+		in   string
+		head rune
+		last rune
+	}{
+		{in: "がっこう", head: 'ガ', last: 'ウ'},
+		{in: "パン", head: 'パ', last: 'ン'},
+		{in: "㋐㋑", head: 'ア', last: 'イ'},
+		{in: "3㌔", head: 'サ', last: 'ロ'},
+		{in: "ヷイン", head: 'ヴ', last: 'ン'},
+		{in: "トㇰ", head: 'ト', last: 'ク'},
+	}
+
+	for _, tt := range tests {
+		hl, err := analyzeHeadAndLast(tt.in, analyzeOptions{normalization: normalizationFull})
+		if err != nil {
+			t.Errorf("analyzeHeadAndLast(%q) returned error: %v", tt.in, err)
+			continue
+		}
+		if hl.head != tt.head || hl.last != tt.last {
+			t.Errorf("analyzeHeadAndLast(%q) = %c, %c; want %c, %c", tt.in, hl.head, hl.last, tt.head, tt.last)
+		}
+	}
+}
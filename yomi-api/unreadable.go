@@ -0,0 +1,132 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/ikawaha/kagome/v2/tokenizer"
+)
+
+// reason why the reading of the text can't be determined.
+type unreadableReason string
+
+const (
+	// the text is empty (after normalization)
+	reasonEmpty unreadableReason = "empty"
+	// the text consists of only URLs or mentions (Nostr IDs)
+	reasonOnlyURLsOrMentions unreadableReason = "onlyUrlsOrMentions"
+	// the text consists of only symbols or emoji
+	reasonOnlySymbols unreadableReason = "onlySymbols"
+	// no kana can be derived from tokens of the text
+	reasonNoKana unreadableReason = "noKana"
+	// the text consists of only numbers that are too long to read (and symbols)
+	reasonNumberTooLong unreadableReason = "numberTooLong"
+	// the reading hint given by the client is not plausible
+	reasonInvalidReadingHint unreadableReason = "invalidReadingHint"
+)
+
+// max number of digits in a number that can be read as the whole text.
+// longer numbers are still read digit by digit if there is anything else to read in the text.
+// on the legacy endpoint (without full normalization), they are always read digit by digit as before.
+const maxReadableNumberDigits = 32
+
+type unreadableError struct {
+	reason unreadableReason
+}
+
+func (e *unreadableError) Error() string {
+	return fmt.Sprintf("unreadable: %s", e.reason)
+}
+
+func newUnreadableError(reason unreadableReason) error {
+	return &unreadableError{reason: reason}
+}
+
+// returns the reason corresponding to the error of analysis. returns empty string for unknown errors.
+func unreadableReasonOf(err error) unreadableReason {
+	var ue *unreadableError
+	if errors.As(err, &ue) {
+		return ue.reason
+	}
+	if errors.Is(err, errInvalidReadingHint) {
+		return reasonInvalidReadingHint
+	}
+	return ""
+}
+
+// checks the text before normalization, to tell why the text is unreadable in detail.
+func checkReadability(s string, opts analyzeOptions) error {
+	if strings.TrimSpace(regexpSpaces.ReplaceAllString(s, " ")) == "" {
+		return newUnreadableError(reasonEmpty)
+	}
+
+	stripped := regexpSpaces.ReplaceAllString(s, " ")
+	stripped = regexpHTTPURI.ReplaceAllString(stripped, " ")
+	stripped = regexpNostrID.ReplaceAllString(stripped, " ")
+	if strings.TrimSpace(stripped) == "" {
+		return newUnreadableError(reasonOnlyURLsOrMentions)
+	}
+	// custom emoji shortcodes are removed in normalization (unless they are read), so the text consisting of only them looks like empty
+	if strings.TrimSpace(replaceCustomEmoji(stripped, opts)) == "" {
+		return newUnreadableError(reasonOnlySymbols)
+	}
+
+	// likewise, emoji are removed in normalization if they are ignored
+	if opts.emoji == emojiIgnore && strings.TrimSpace(replaceEmoji(replaceCustomEmoji(stripped, opts), emojiIgnore)) == "" {
+		return newUnreadableError(reasonOnlySymbols)
+	}
+
+	// too long numbers are the reason only if head/last can't be derived from anything else
+	if opts.normalization != normalizationFull {
+		return nil
+	}
+	hasTooLongNumber := false
+	rest := regexpNumber.ReplaceAllStringFunc(fullwidthDigitsToASCII(stripped), func(n string) string {
+		digits := 0
+		for _, r := range n {
+			if unicode.IsDigit(r) {
+				digits++
+			}
+		}
+		if digits > maxReadableNumberDigits {
+			hasTooLongNumber = true
+			return " "
+		}
+		return n
+	})
+	if hasTooLongNumber && !strings.ContainsFunc(rest, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsNumber(r) }) {
+		return newUnreadableError(reasonNumberTooLong)
+	}
+	return nil
+}
+
+// determines the reason why no head/last kana can be derived from the tokens.
+func unreadableReasonOfTokens(tokens []tokenizer.Token) unreadableReason {
+	nonSpace := 0
+	for _, t := range tokens {
+		if strings.TrimSpace(t.Surface) == "" {
+			continue
+		}
+		nonSpace++
+		if !isSymbolToken(t) {
+			return reasonNoKana
+		}
+	}
+	if nonSpace == 0 {
+		return reasonEmpty
+	}
+	return reasonOnlySymbols
+}
+
+// checks if the token is a symbol or an emoji, which doesn't contain any letters or digits.
+// note that POS of the token is not reliable for this purpose, since the tokenizer classifies unknown letters (e.g. Hangul) as symbols.
+func isSymbolToken(t tokenizer.Token) bool {
+	for _, r := range t.Surface {
+		if unicode.IsLetter(r) || unicode.IsNumber(r) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"log"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestAnalyzeHeadAndLast_unreadableReason(t *testing.T) {
+	if err := initialize(); err != nil {
+		log.Fatal(err)
+	}
+
+	tests := []struct {
+		in   string
+		opts analyzeOptions
+		want unreadableReason
+	}{
+		{in: "", want: reasonEmpty},
+		{in: " 　\n", want: reasonEmpty},
+		{in: "https://example.com/image.png", want: reasonOnlyURLsOrMentions},
+		{in: "nostr:npub168ghgug469n4r2tuyw05dmqhqv5jcwm7nxytn67afmz8qkc4a4zqsu2dlc https://example.com", want: reasonOnlyURLsOrMentions},
+		{in: "！？", want: reasonOnlySymbols},
+		{in: ":wayo: :pizza:", want: reasonOnlySymbols},
+		{in: ":wayo: :pizza:", opts: analyzeOptions{readCustomEmoji: true, customEmojis: []string{"sushi"}}, want: reasonOnlySymbols},
+		{in: "◆◇◆", want: reasonOnlySymbols},
+		{in: "한국어", want: reasonNoKana},
+		{in: strings.Repeat("9", maxReadableNumberDigits+1), opts: analyzeOptions{normalization: normalizationFull}, want: reasonNumberTooLong},
+		{in: strings.Repeat("9", maxReadableNumberDigits+1) + "！", opts: analyzeOptions{normalization: normalizationFull}, want: reasonNumberTooLong},
+		{in: "日本", opts: analyzeOptions{readingHint: "ヤマト"}, want: reasonInvalidReadingHint},
+	}
+
+	for _, tt := range tests {
+		_, err := analyzeHeadAndLast(tt.in, tt.opts)
+		if err == nil {
+			t.Errorf("analyzeHeadAndLast(%q) got no error; want error with reason %q", tt.in, tt.want)
+			continue
+		}
+		if got := unreadableReasonOf(err); got != tt.want {
+			t.Errorf("analyzeHeadAndLast(%q) got error with reason %q (%v); want %q", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+// too long numbers don't make the text unreadable if there is anything else to read.
+func TestAnalyzeHeadAndLast_tooLongNumberWithText(t *testing.T) {
+	if err := initialize(); err != nil {
+		log.Fatal(err)
+	}
+
+	long := strings.Repeat("9", maxReadableNumberDigits+1)
+	tests := []struct {
+		in   string
+		head rune
+		last rune
+	}{
+		{in: "りんご" + long, head: 'リ', last: 'ウ'},
+		{in: long + "りんご", head: 'キ', last: 'ゴ'},
+	}
+
+	for _, tt := range tests {
+		hl, err := analyzeHeadAndLast(tt.in, analyzeOptions{})
+		if err != nil {
+			t.Errorf("analyzeHeadAndLast(%q) returned error: %v", tt.in, err)
+			continue
+		}
+		if hl.head != tt.head || hl.last != tt.last {
+			t.Errorf("analyzeHeadAndLast(%q) = %c, %c; want %c, %c", tt.in, hl.head, hl.last, tt.head, tt.last)
+		}
+	}
+}
+
+// on the legacy endpoint, too long numbers are read digit by digit even if there is nothing else to read.
+func TestAnalyzeHeadAndLast_tooLongNumberOnLegacyEndpoint(t *testing.T) {
+	if err := initialize(); err != nil {
+		log.Fatal(err)
+	}
+
+	q, _ := url.ParseQuery("c=" + strings.Repeat("9", maxReadableNumberDigits+1))
+	opts, err := parseAnalyzeOptions(q)
+	if err != nil {
+		t.Fatalf("parseAnalyzeOptions returned error: %v", err)
+	}
+	hl, err := analyzeHeadAndLast(q.Get("c"), opts)
+	if err != nil {
+		t.Fatalf("analyzeHeadAndLast(%q) returned error: %v", q.Get("c"), err)
+	}
+	if hl.head != 'キ' || hl.last != 'ウ' {
+		t.Errorf("analyzeHeadAndLast(%q) = %c, %c; want キ, ウ", q.Get("c"), hl.head, hl.last)
+	}
+}
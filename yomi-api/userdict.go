@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/ikawaha/kagome-dict/dict"
+	"github.com/ikawaha/kagome/v2/tokenizer"
+)
+
+var (
+	// path to kagome user dictionary file. user dictionary is disabled if empty.
+	//
+	// the file follows the format of kagome user dictionary:
+	//
+	//	<surface>,<segmented surface>,<readings of segments>,<POS>
+	//
+	// e.g. "野州,野州,ヤシュウ,名詞". readings of segments are concatenated to make the reading of the word.
+	userDictPath string
+
+	// guards kagomeTokenizer, which is replaced as a whole on reload of the user dictionary.
+	tokenizerMu sync.RWMutex
+)
+
+// builds kagome tokenizer with the system dictionary, along with the user dictionary if configured.
+func newKagomeTokenizer(d *dict.Dict) (*tokenizer.Tokenizer, error) {
+	opts := []tokenizer.Option{tokenizer.OmitBosEos()}
+	if userDictPath != "" {
+		udict, err := dict.NewUserDict(userDictPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load user dictionary: %w", err)
+		}
+		opts = append(opts, tokenizer.UserDict(udict))
+	}
+	t, err := tokenizer.New(d, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize kagome tokenizer: %w", err)
+	}
+	return t, nil
+}
+
+func currentTokenizer() *tokenizer.Tokenizer {
+	tokenizerMu.RLock()
+	defer tokenizerMu.RUnlock()
+	return kagomeTokenizer
+}
+
+// reloads the user dictionary and swaps the tokenizer.
+// if loading fails, the current tokenizer is kept.
+func reloadUserDict() error {
+	t, err := newKagomeTokenizer(kagomeDict)
+	if err != nil {
+		return err
+	}
+
+	tokenizerMu.Lock()
+	kagomeTokenizer = t
+	tokenizerMu.Unlock()
+
+	// tokenizers for comparison will be recreated with the new user dictionary on next use
+	comparisonTokenizersMu.Lock()
+	clear(comparisonTokenizers)
+	comparisonTokenizersMu.Unlock()
+	return nil
+}
+
+// returns reading of the token.
+// kagome doesn't provide reading of words in the user dictionary via Token.Reading(), so it is taken from readings of segments.
+func readingOfToken(t tokenizer.Token) (string, bool) {
+	if ex := t.UserExtra(); ex != nil {
+		r := hiraganaToKatakana(strings.Join(ex.Readings, ""))
+		return r, r != ""
+	}
+	return t.Reading()
+}
+
+// checks if the token is a word in the user dictionary.
+func isUserDictToken(t tokenizer.Token) bool {
+	return t.Class == tokenizer.USER
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestUserDict(t *testing.T) {
+	if err := initialize(); err != nil {
+		log.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "user.dic")
+	dic := "# comment\n" +
+		"海空鮫,海空鮫,ミソラザメ,名詞\n" +
+		"のすっ子ちゃん,のすっ子 ちゃん,のすっこ ちゃん,名詞\n"
+	if err := os.WriteFile(path, []byte(dic), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	userDictPath = path
+	defer func() {
+		userDictPath = ""
+		if err := reloadUserDict(); err != nil {
+			log.Fatal(err)
+		}
+	}()
+	if err := reloadUserDict(); err != nil {
+		t.Fatalf("reloadUserDict returned error: %v", err)
+	}
+
+	tests := []struct {
+		in      string
+		opts    analyzeOptions
+		wantErr bool
+		head    rune
+		last    rune
+	}{
+		{in: "海空鮫", head: 'ミ', last: 'メ'},
+		{in: "あれは海空鮫", head: 'ア', last: 'メ'},
+		{in: "のすっ子ちゃん", head: 'ノ', last: 'ン'},
+		{in: "海空鮫", opts: analyzeOptions{readingHint: "みそらざめ"}, head: 'ミ', last: 'メ'},
+		{in: "海空鮫", opts: analyzeOptions{readingHint: "うみそらさめ"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		hl, err := analyzeHeadAndLast(tt.in, tt.opts)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("analyzeHeadAndLast(%q, %+v) = %c, %c; want error", tt.in, tt.opts, hl.head, hl.last)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("analyzeHeadAndLast(%q, %+v) returned error: %v", tt.in, tt.opts, err)
+			continue
+		}
+		if hl.head != tt.head || hl.last != tt.last {
+			t.Errorf("analyzeHeadAndLast(%q, %+v) = %c, %c; want %c, %c", tt.in, tt.opts, hl.head, hl.last, tt.head, tt.last)
+		}
+	}
+
+	// candidates of words in the user dictionary are only the reading in it
+	hl, err := analyzeHeadAndLast("海空鮫", analyzeOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if heads, lasts := hl.candidates(analyzeOptions{}); len(heads) != 1 || len(lasts) != 1 {
+		t.Errorf("candidates = %q, %q; want only the reading in the user dictionary", heads, lasts)
+	}
+
+	// broken user dictionary is rejected, and the current tokenizer is kept
+	if err := os.WriteFile(path, []byte("broken entry\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := reloadUserDict(); err == nil {
+		t.Errorf("reloadUserDict succeeded with broken user dictionary; want error")
+	}
+	if hl, err := analyzeHeadAndLast("海空鮫", analyzeOptions{}); err != nil || hl.head != 'ミ' {
+		t.Errorf("user dictionary is not kept after failed reload")
+	}
+}